@@ -0,0 +1,67 @@
+package game
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"pyramid_solver_go_local/utils"
+)
+
+// ParsePuzzleCSV reads a puzzle exported as CSV by a board scanner, one stone value (1-13) per
+// cell. The first MaxPyramidRows records are the pyramid rows from the apex (G) down to the
+// base (A), each left to right; the remaining records are the draw pile segments in order, each
+// of StonesPerSegment stones except that the last may be shorter. Empty cells and records
+// without values, such as a blank line between the sections, are ignored. The stones are
+// returned in the order SetupCustomGame takes them: the pyramid from A1 to G1, then the draw pile.
+func ParsePuzzleCSV(r io.Reader) (pyramid []int, draw []int, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := [][]string{}
+	for _, record := range records {
+		if values := strings.Fields(strings.Join(record, " ")); len(values) > 0 {
+			rows = append(rows, values)
+		}
+	}
+	if len(rows) < MaxPyramidRows {
+		return nil, nil, fmt.Errorf("%w: expected %d pyramid rows, got %d", utils.ErrWrongLength, MaxPyramidRows, len(rows))
+	}
+
+	pyramidRows := make([][]int, MaxPyramidRows)
+	for i, values := range rows[:MaxPyramidRows] {
+		rowIdx := MaxPyramidRows - 1 - i
+		stones, err := utils.ParseInts(strings.Join(values, " "), utils.PyramidRowSizes[rowIdx])
+		if err != nil {
+			return nil, nil, fmt.Errorf("pyramid row %c: %w", 'A'+rowIdx, err)
+		}
+		pyramidRows[rowIdx] = stones
+	}
+	for _, stones := range pyramidRows {
+		pyramid = append(pyramid, stones...)
+	}
+
+	segments := rows[MaxPyramidRows:]
+	if len(segments) > MaxDrawPileSegments {
+		return nil, nil, fmt.Errorf("%w: expected at most %d draw pile segments, got %d", utils.ErrWrongLength, MaxDrawPileSegments, len(segments))
+	}
+	draw = []int{}
+	for i, values := range segments {
+		count := StonesPerSegment
+		if i == len(segments)-1 && len(values) < StonesPerSegment {
+			count = len(values)
+		}
+		stones, err := utils.ParseInts(strings.Join(values, " "), count)
+		if err != nil {
+			return nil, nil, fmt.Errorf("draw pile segment %d: %w", i+1, err)
+		}
+		draw = append(draw, stones...)
+	}
+	return pyramid, draw, nil
+}
@@ -0,0 +1,67 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"pyramid_solver_go_local/utils"
+)
+
+// exampleCSV is the example puzzle as a board scanner exports it: the pyramid apex first, a
+// blank line, then the draw pile segments.
+const exampleCSV = `13
+10,12
+2,13,9
+7,2,9,6
+5,10,8,11,9
+11,5,1,4,1,4
+12,10,11,6,11,7,12
+
+6,3,8
+9,3,10
+2,13,6
+7,1,13
+12,4,1
+2,3,8
+5,3,5
+7,3,8
+`
+
+func TestParsePuzzleCSV(t *testing.T) {
+	pyramid, draw, err := ParsePuzzleCSV(strings.NewReader(exampleCSV))
+	if err != nil {
+		t.Fatalf("ParsePuzzleCSV: %v", err)
+	}
+	if !reflect.DeepEqual(pyramid, examplePyramid) {
+		t.Errorf("pyramid = %v, want %v", pyramid, examplePyramid)
+	}
+	if !reflect.DeepEqual(draw, exampleDrawPile) {
+		t.Errorf("draw pile = %v, want %v", draw, exampleDrawPile)
+	}
+
+	short, _, err := ParsePuzzleCSV(strings.NewReader(strings.TrimSuffix(exampleCSV, "7,3,8\n") + "7, 3\n"))
+	if err != nil || len(short) != TotalPyramidStones {
+		t.Errorf("a short last segment was rejected: %v", err)
+	}
+}
+
+func TestParsePuzzleCSVErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want error
+	}{
+		{"too few pyramid rows", "13\n10,12\n", utils.ErrWrongLength},
+		{"short pyramid row", strings.Replace(exampleCSV, "2,13,9", "2,13", 1), utils.ErrWrongLength},
+		{"value out of range", strings.Replace(exampleCSV, "10,12", "10,14", 1), utils.ErrOutOfRange},
+		{"short middle segment", strings.Replace(exampleCSV, "9,3,10", "9,3", 1), utils.ErrWrongLength},
+		{"too many segments", exampleCSV + "1,2,3\n", utils.ErrWrongLength},
+	}
+	for _, tt := range tests {
+		if _, _, err := ParsePuzzleCSV(strings.NewReader(tt.csv)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error %v does not wrap %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !pyramid_debug
+
+package game
+
+// debugChecks enables internal consistency checks; build with -tags pyramid_debug to turn
+// them on.
+const debugChecks = false
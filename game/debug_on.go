@@ -0,0 +1,6 @@
+//go:build pyramid_debug
+
+package game
+
+// debugChecks enables internal consistency checks; see debugValidateDrawPile.
+const debugChecks = true
@@ -2,8 +2,11 @@ package game
 
 
 import (
+   "encoding/binary"
    "fmt"
+   "hash/fnv"
    "math"
+   "math/rand"
    "strings"
 
 
@@ -12,8 +15,8 @@ import (
 
 
 const (
-   MaxDrawPileSegments = 8
-   StonesPerSegment    = 3
+   MaxDrawPileSegments = 8 // Default number of draw pile segments
+   StonesPerSegment    = 3 // Default number of stones per draw pile segment
    TotalPyramidStones  = 28
    MaxPyramidRows      = 7
    MaxPyramidCols      = 7 // Max columns in any row (for array sizing)
@@ -22,8 +25,8 @@ const (
 
 // Move represents a game move.
 type Move struct {
-   Source      string
-   Destination string
+   Source      string `json:"source"`
+   Destination string `json:"destination"`
 }
 
 
@@ -31,7 +34,9 @@ type Move struct {
 type PuzzleGame struct {
    pyramid             [MaxPyramidRows][MaxPyramidCols]int // Stores stone values, -1 for empty
    hold                int                                 // -1 for empty
-   drawPile            [MaxDrawPileSegments][]int          // Array of slices for segments
+   drawPile            [][]int                             // One slice per segment
+   numSegments         int                                 // Configured segment count (len(drawPile))
+   stonesPerSegment    int                                 // Configured segment capacity
    currentSegment      int
    moves               []Move
    matches             int
@@ -40,21 +45,90 @@ type PuzzleGame struct {
    redraws             int
    timeRemaining       int // Fixed at 120 for scoring
    numActiveSegments   int // Actual number of active segments in drawPile
+   streakPolicy        StreakResetPolicy
+   scoringRules        ScoringRules
+   allowHoldPlacement  bool   // Variant rule: HOLD -> empty pyramid slot, see SetAllowHoldPlacement
+   initialPuzzle       string // Puzzle letters as passed to SetupCustomGame
+   initialHash         uint64 // Hash of the puzzle as dealt, for Fingerprint; 0 before setup
 }
 
 
+// StreakResetPolicy selects which non-clearing moves break the current streak, so rule
+// variants can be modelled. Clearing moves always extend the streak.
+type StreakResetPolicy struct {
+	ResetOnDraw     bool // A DRAW breaks the streak
+	ResetOnHoldPark bool // Parking a stone in an empty HOLD breaks the streak
+	ResetOnNonMatch bool // Any other move that clears nothing breaks the streak
+}
+
+
+// DefaultStreakResetPolicy is the standard rule: draws and failed moves break the streak,
+// parking in HOLD does not.
+var DefaultStreakResetPolicy = StreakResetPolicy{ResetOnDraw: true, ResetOnNonMatch: true}
+
+
+// ScoringRules sets what the stones left over once the pyramid is solved are worth, so rule
+// variants that value an unused draw stone and a stone kept in HOLD differently can be modelled.
+type ScoringRules struct {
+	LeftoverDrawStone int // Points per stone left in the draw pile
+	LeftoverHoldStone int // Points for a stone left in HOLD
+}
+
+
+// DefaultScoringRules is the standard rule: 50 points per leftover stone, wherever it is.
+var DefaultScoringRules = ScoringRules{LeftoverDrawStone: 50, LeftoverHoldStone: 50}
+
 
 
-// NewPuzzleGame creates and initializes a new PuzzleGame.
+
+// NewPuzzleGame creates and initializes a new PuzzleGame with the standard draw pile layout.
 func NewPuzzleGame() *PuzzleGame {
-   game := &PuzzleGame{
-       hold:          -1, // -1 indicates empty hold
-       timeRemaining: 120,
-   }
-   game.initializePyramid()
-   return game
+   return NewPuzzleGameWithDrawLayout(MaxDrawPileSegments, StonesPerSegment)
+}
+
+
+// NewPuzzleGameWithDrawLayout creates a PuzzleGame whose draw pile has the given number of
+// segments and stones per segment, for rule variants. Values below 1 fall back to the defaults.
+func NewPuzzleGameWithDrawLayout(segments, stonesPerSegment int) *PuzzleGame {
+	if segments < 1 {
+		segments = MaxDrawPileSegments
+	}
+	if stonesPerSegment < 1 {
+		stonesPerSegment = StonesPerSegment
+	}
+	game := &PuzzleGame{
+		hold:             -1, // -1 indicates empty hold
+		drawPile:         make([][]int, segments),
+		numSegments:      segments,
+		stonesPerSegment: stonesPerSegment,
+		timeRemaining:    120,
+		streakPolicy:     DefaultStreakResetPolicy,
+		scoringRules:     DefaultScoringRules,
+	}
+	game.initializePyramid()
+	return game
 }
 
+// GameConfig describes the layout and rules a PuzzleGame was set up with, so UIs and serializers
+// can size themselves to it.
+type GameConfig struct {
+	Rows             int // Pyramid rows, A to G
+	PyramidStones    int // Stones in a full pyramid
+	Segments         int // Draw pile segments
+	StonesPerSegment int // Capacity of each draw pile segment
+	TimeRemaining    int // Time left for the time bonus
+}
+
+// Config returns the layout of g.
+func (g *PuzzleGame) Config() GameConfig {
+	return GameConfig{
+		Rows:             MaxPyramidRows,
+		PyramidStones:    TotalPyramidStones,
+		Segments:         g.numSegments,
+		StonesPerSegment: g.stonesPerSegment,
+		TimeRemaining:    g.timeRemaining,
+	}
+}
 
 // initializePyramid sets up the initial empty pyramid structure.
 func (g *PuzzleGame) initializePyramid() {
@@ -88,48 +162,61 @@ func (g *PuzzleGame) SetupRandomGame() {
 
 
    // Fill the draw pile
-   // Corrected loop header: segmentIdx is declared once
-   for segmentIdx := 0; segmentIdx < MaxDrawPileSegments; segmentIdx++ {
-       start := TotalPyramidStones + segmentIdx*StonesPerSegment
-       end := start + StonesPerSegment
-       if end > len(stones) { // Handle cases where not enough stones for full segments
-           end = len(stones)
-       }
-       g.drawPile[segmentIdx] = stones[start:end]
-   }
-   g._trimEmptySegments()
+   g.fillDrawPile(stones[TotalPyramidStones:])
+   g.recordInitialHash()
 }
 
 
-// SetupCustomGame sets up the game with user-provided values.
+// SetupCustomGame sets up the game with user-provided values. Draw pile stones that cannot be
+// seen may be given as utils.UnknownStone; see SampleUnknownDrawStones. Pyramid stones must all
+// be known.
 func (g *PuzzleGame) SetupCustomGame(pyramidStones, drawPileStones []int) error {
    if len(pyramidStones) != TotalPyramidStones {
-       return fmt.Errorf("pyramid must have %d stones, got %d", TotalPyramidStones, len(pyramidStones))
+       return fmt.Errorf("%w: pyramid must have %d stones, got %d", utils.ErrWrongLength, TotalPyramidStones, len(pyramidStones))
+   }
+   for i, stone := range pyramidStones {
+       if stone == utils.UnknownStone {
+           return fmt.Errorf("%w: pyramid stone %d is unknown; only draw pile stones may be", utils.ErrOutOfRange, i+1)
+       }
    }
-   if len(drawPileStones) > MaxDrawPileSegments*StonesPerSegment { // Allow fewer than 24 if user provides
-       return fmt.Errorf("draw pile must have at most %d stones, got %d", MaxDrawPileSegments*StonesPerSegment, len(drawPileStones))
+   if len(drawPileStones) > g.numSegments*g.stonesPerSegment { // Allow fewer than a full pile if user provides
+       return fmt.Errorf("%w: draw pile must have at most %d stones, got %d", utils.ErrWrongLength, g.numSegments*g.stonesPerSegment, len(drawPileStones))
    }
 
 
+   g.initialPuzzle = utils.StonesToLetters(pyramidStones) + "\n" + utils.StonesToLetters(drawPileStones)
    pyramidPositions := g.getAllPyramidPositions()
    for i := 0; i < TotalPyramidStones; i++ {
        g.pyramid[pyramidPositions[i][0]][pyramidPositions[i][1]] = pyramidStones[i]
    }
 
 
-   for segmentIdx := 0; segmentIdx < MaxDrawPileSegments; segmentIdx++ {
-       start := segmentIdx * StonesPerSegment
-       end := start + StonesPerSegment
-       if end > len(drawPileStones) {
-           end = len(drawPileStones)
-       }
-       g.drawPile[segmentIdx] = drawPileStones[start:end]
-   }
-   g._trimEmptySegments()
+   g.fillDrawPile(drawPileStones)
+   g.recordInitialHash()
    return nil
 }
 
 
+// fillDrawPile deals copies of stones into consecutive segments, leaving any segments past the
+// last stone empty, so later changes to the pile never write through to the caller's slice.
+// Stones beyond the pile's capacity are dropped.
+func (g *PuzzleGame) fillDrawPile(stones []int) {
+	for segmentIdx := range g.drawPile {
+		start := segmentIdx * g.stonesPerSegment
+		end := start + g.stonesPerSegment
+		if start > len(stones) { // Not enough stones to reach this segment
+			start = len(stones)
+		}
+		if end > len(stones) {
+			end = len(stones)
+		}
+		g.drawPile[segmentIdx] = append([]int(nil), stones[start:end]...)
+	}
+	g._trimEmptySegments()
+	g.debugValidateDrawPile()
+}
+
+
 // getAllPyramidPositions returns a slice of [row, col] indices for all pyramid positions.
 // Row 0 is 'A' (bottom), Row 6 is 'G' (top).
 func (g *PuzzleGame) getAllPyramidPositions() [][2]int {
@@ -143,6 +230,20 @@ func (g *PuzzleGame) getAllPyramidPositions() [][2]int {
 }
 
 
+// PositionsTopDown returns every pyramid position in display order: rows from the apex (G1)
+// down to the base, each row left to right, ending at A7.
+func (g *PuzzleGame) PositionsTopDown() []string {
+	positions := make([]string, 0, TotalPyramidStones)
+	for rowIdx := MaxPyramidRows - 1; rowIdx >= 0; rowIdx-- {
+		for colIdx := 0; colIdx < utils.PyramidRowSizes[rowIdx]; colIdx++ {
+			pos, _ := utils.IndicesToString(rowIdx, colIdx)
+			positions = append(positions, pos)
+		}
+	}
+	return positions
+}
+
+
 // IsMatchingPair checks if two stones form a valid matching pair.
 func (g *PuzzleGame) IsMatchingPair(stone1, stone2 int) bool {
    if stone1 == -1 || stone2 == -1 || stone1 == 13 || stone2 == 13 {
@@ -161,14 +262,102 @@ func (g *PuzzleGame) IsMatchingPair(stone1, stone2 int) bool {
 // IsAccessible checks if a pyramid position is accessible.
 // Row 0 is 'A' (bottom), Row 6 is 'G' (top).
 func (g *PuzzleGame) IsAccessible(rowIdx, colIdx int) bool {
+   if rowIdx < 0 || rowIdx >= MaxPyramidRows || colIdx < 0 || colIdx >= utils.PyramidRowSizes[rowIdx] {
+       return false // Not a pyramid position
+   }
    if g.pyramid[rowIdx][colIdx] == -1 { // Position is empty
        return false
    }
    if rowIdx == 0 { // Row A (bottom, index 0) is always accessible
        return true
    }
-   // Check if the two positions below are empty
-   return g.pyramid[rowIdx-1][colIdx] == -1 && g.pyramid[rowIdx-1][colIdx+1] == -1
+   // Check if the two positions below are empty. Both are bound-checked against the row below
+   // rather than relying on the spare -1 cells of the fixed-size array; a cell outside that
+   // row holds no stone, so it cannot block.
+   belowSize := utils.PyramidRowSizes[rowIdx-1]
+   leftEmpty := colIdx >= belowSize || g.pyramid[rowIdx-1][colIdx] == -1
+   rightEmpty := colIdx+1 >= belowSize || g.pyramid[rowIdx-1][colIdx+1] == -1
+   return leftEmpty && rightEmpty
+}
+
+
+// BlockingDepth returns how many stones currently block the position at (row, col), directly
+// or transitively: the stones in the rows below it that must all be cleared before it becomes
+// accessible. Row A (row 0) is always accessible, so its depth is 0, while the apex G1 is
+// blocked by every other stone left in the pyramid. It returns -1 for a position outside the
+// pyramid.
+func (g *PuzzleGame) BlockingDepth(row, col int) int {
+	if row < 0 || row >= MaxPyramidRows || col < 0 || col >= utils.PyramidRowSizes[row] {
+		return -1
+	}
+	depth := 0
+	// The blocking cone widens by one column per row: row-k covers columns col to col+k
+	for k := 1; k <= row; k++ {
+		for c := col; c <= col+k; c++ {
+			if g.pyramid[row-k][c] != -1 {
+				depth++
+			}
+		}
+	}
+	return depth
+}
+
+// RemainingCentroid returns the centre of mass of the stones left in the pyramid. row is the
+// mean row index (0 for A), and col the mean horizontal position in stone widths as the pyramid
+// is drawn, col+row/2, so an untouched pyramid is centred on col 3 and clearing the left side
+// moves col higher. It returns (-1, -1) when the pyramid is empty.
+func (g *PuzzleGame) RemainingCentroid() (row float64, col float64) {
+	stones := 0
+	for rowIdx := 0; rowIdx < MaxPyramidRows; rowIdx++ {
+		for colIdx := 0; colIdx < utils.PyramidRowSizes[rowIdx]; colIdx++ {
+			if g.pyramid[rowIdx][colIdx] != -1 {
+				row += float64(rowIdx)
+				col += float64(colIdx) + float64(rowIdx)/2
+				stones++
+			}
+		}
+	}
+	if stones == 0 {
+		return -1, -1
+	}
+	return row / float64(stones), col / float64(stones)
+}
+
+
+// MatchChains is a rough structural measure of how constrained the board is. A chain is a
+// sequence of stones each resting on the next, from a stone nothing rests on down to one that
+// rests on nothing, so its matches have to happen bottom first; MatchChains counts the distinct
+// chains among the remaining pyramid stones. A lone stone is a chain of its own, so a board of
+// scattered row A stones has one chain per stone, while a full pyramid has 64 chains from G1
+// alone. More chains, and longer ones, mean more matches are tied to others. It only looks at
+// blocking, not at which values can pair up.
+func (g *PuzzleGame) MatchChains() int {
+	occupied := func(row, col int) bool {
+		return row >= 0 && row < MaxPyramidRows && col >= 0 && col < utils.PyramidRowSizes[row] &&
+			g.pyramid[row][col] != -1
+	}
+	// paths[r][c] counts the chains from (r, c) down, filled in from row A upwards
+	var paths [MaxPyramidRows][MaxPyramidCols]int
+	chains := 0
+	for rowIdx := 0; rowIdx < MaxPyramidRows; rowIdx++ {
+		for colIdx := 0; colIdx < utils.PyramidRowSizes[rowIdx]; colIdx++ {
+			if !occupied(rowIdx, colIdx) {
+				continue
+			}
+			for _, below := range []int{colIdx, colIdx + 1} {
+				if occupied(rowIdx-1, below) {
+					paths[rowIdx][colIdx] += paths[rowIdx-1][below]
+				}
+			}
+			if paths[rowIdx][colIdx] == 0 {
+				paths[rowIdx][colIdx] = 1 // Rests on nothing
+			}
+			if !occupied(rowIdx+1, colIdx-1) && !occupied(rowIdx+1, colIdx) {
+				chains += paths[rowIdx][colIdx] // Nothing rests on it, so its chains end here
+			}
+		}
+	}
+	return chains
 }
 
 
@@ -191,27 +380,92 @@ func (g *PuzzleGame) GetAccessiblePositions() []string {
 
 
 func (g *PuzzleGame) GetCurrentDrawStone() int {
-  
+	_, stone := g.CurrentDrawSource()
+	return stone
+}
 
+// CurrentDrawSource returns the current draw stone together with the index of
+// the segment it comes from. When the current segment is empty the stone is
+// backfilled from the nearest earlier segment, so segment may be lower than
+// the current one. Both values are -1 when no draw stone is available.
+func (g *PuzzleGame) CurrentDrawSource() (segment int, value int) {
+	for seg := g.currentSegment; seg >= 0; seg-- {
+		if len(g.drawPile[seg]) > 0 {
+			return seg, g.drawPile[seg][len(g.drawPile[seg])-1]
+		}
+	}
+	return -1, -1
+}
 
-   if len(g.drawPile[g.currentSegment]) > 0 {
-       stone := g.drawPile[g.currentSegment][len(g.drawPile[g.currentSegment])-1]
-       return stone
-   }
 
+// DrawOrder returns the stones left in the draw pile in the order they would show as DRW1 if
+// each were used up before drawing again: the current segment from its top, then the earlier
+// segments it backfills from, nearest first, then each later segment in turn as DRAW reaches
+// it. The stones a redraw would bring back are not included.
+func (g *PuzzleGame) DrawOrder() []int {
+	order := []int{}
+	appendSegment := func(seg int) {
+		for i := len(g.drawPile[seg]) - 1; i >= 0; i-- {
+			order = append(order, g.drawPile[seg][i])
+		}
+	}
+	for seg := g.currentSegment; seg >= 0; seg-- {
+		appendSegment(seg)
+	}
+	for seg := g.currentSegment + 1; seg < g.numActiveSegments; seg++ {
+		appendSegment(seg)
+	}
+	return order
+}
 
-   // Backfill only if the current segment is empty
-   if len(g.drawPile[g.currentSegment]) == 0 {
-       for seg := g.currentSegment - 1; seg >= 0; seg-- {
-           if len(g.drawPile[seg]) > 0 {
-               stone := g.drawPile[seg][len(g.drawPile[seg])-1]
-               return stone
-           }
-       }
-   }
 
+// AccessibleThirteens returns the positions of accessible 13s, which can only be smashed.
+// "DRW1" is appended when the current draw stone is a 13.
+func (g *PuzzleGame) AccessibleThirteens() []string {
+	thirteens := []string{}
+	for rowIdx := 0; rowIdx < MaxPyramidRows; rowIdx++ {
+		for colIdx := 0; colIdx < utils.PyramidRowSizes[rowIdx]; colIdx++ {
+			if g.IsAccessible(rowIdx, colIdx) && g.pyramid[rowIdx][colIdx] == 13 {
+				pos, _ := utils.IndicesToString(rowIdx, colIdx)
+				thirteens = append(thirteens, pos)
+			}
+		}
+	}
+	if g.GetCurrentDrawStone() == 13 {
+		thirteens = append(thirteens, "DRW1")
+	}
+	return thirteens
+}
 
-   return -1 // No stone available
+
+// PlanSmashOrder suggests an order for smashing the pyramid's 13s, chosen greedily: at each step
+// it smashes the accessible 13 that exposes the most new accessible positions, then looks again,
+// so 13s uncovered along the way are included. Ties go to the earlier position in
+// AccessibleThirteens. A 13 on DRW1 exposes nothing and is left out. g is not modified.
+func (g *PuzzleGame) PlanSmashOrder() []string {
+	order := []string{}
+	board := g.DeepCopy()
+	trial := g.DeepCopy()
+	for {
+		best, bestExposed := "", -1
+		before := len(board.GetAccessiblePositions())
+		for _, pos := range board.AccessibleThirteens() {
+			if pos == "DRW1" {
+				continue
+			}
+			trial.Reset(board)
+			trial.MakeMove(pos, "SMASH")
+			// The smashed 13 itself is no longer accessible, so add it back
+			if exposed := len(trial.GetAccessiblePositions()) - before + 1; exposed > bestExposed {
+				best, bestExposed = pos, exposed
+			}
+		}
+		if best == "" {
+			return order
+		}
+		board.MakeMove(best, "SMASH")
+		order = append(order, best)
+	}
 }
 
 
@@ -232,7 +486,9 @@ func (g *PuzzleGame) MakeMove(source, destination string) bool {
            g._redistributeDrawPile()                // Redistribute and update numActiveSegments
            g._trimEmptySegments()
        }
-       g.streak = 0
+       if g.streakPolicy.ResetOnDraw {
+           g.streak = 0
+       }
        return false
    }
 
@@ -270,13 +526,7 @@ func (g *PuzzleGame) MakeMove(source, destination string) bool {
       }
       g.matches++
       g.streak++
-      if g.streak > 1 { // Only add bonus if streak is 2 or more
-          if g.streak >= 5 { // Cap at 200 for 5th match and greater
-              g.streakBonus += 200
-          } else { // For 2nd, 3rd, 4th match
-              g.streakBonus += (g.streak - 1) * 50
-          }
-      }
+      g.streakBonus += streakBonusFor(g.streak)
       return true // Stone cleared
   }
 
@@ -361,13 +611,7 @@ func (g *PuzzleGame) MakeMove(source, destination string) bool {
 
       g.matches++
       g.streak++
-      if g.streak > 1 { // Only add bonus if streak is 2 or more
-          if g.streak >= 5 { // Cap at 200 for 5th match and greater
-              g.streakBonus += 200
-          } else { // For 2nd, 3rd, 4th match
-              g.streakBonus += (g.streak - 1) * 50
-          }
-      }
+      g.streakBonus += streakBonusFor(g.streak)
       return true // Stone cleared
   }
 
@@ -396,14 +640,30 @@ func (g *PuzzleGame) MakeMove(source, destination string) bool {
           row, col, _ := utils.StringToIndices(source) // Error handling omitted for brevity
           g.pyramid[row][col] = -1
       }
+      if g.streakPolicy.ResetOnHoldPark {
+          g.streak = 0
+      }
       return false // Not a stone-clearing move
   }
 
 
 
 
+  // Hold placement variant: put the held stone back into an empty slot
+  if source == "HOLD" {
+      if row, col, err := utils.StringToIndices(destination); err == nil && g.canPlaceHold(row, col) {
+          g.pyramid[row][col] = g.hold
+          g.hold = -1
+      }
+  }
+
+
+
+
   // If it's neither a match nor a valid move to HOLD, it's a non-clearing move
-  g.streak = 0 // Streak resets for any other non-clearing move
+  if g.streakPolicy.ResetOnNonMatch {
+      g.streak = 0 // Streak resets for any other non-clearing move
+  }
   return false // Not a stone-clearing move
 }
 
@@ -418,36 +678,283 @@ func (g *PuzzleGame) _redistributeDrawPile() {
    }
 
 
-   g.drawPile = [MaxDrawPileSegments][]int{} // Clear draw pile
+   g.drawPile = make([][]int, g.numSegments) // Clear draw pile
    g.numActiveSegments = 0
 
 
    segmentIdx := 0
    for _, stone := range allStones {
-       if segmentIdx < MaxDrawPileSegments {
-           if len(g.drawPile[segmentIdx]) < StonesPerSegment {
+       if segmentIdx < g.numSegments {
+           if len(g.drawPile[segmentIdx]) < g.stonesPerSegment {
                g.drawPile[segmentIdx] = append(g.drawPile[segmentIdx], stone)
            } else {
                segmentIdx++
-               if segmentIdx < MaxDrawPileSegments {
+               if segmentIdx < g.numSegments {
                    g.drawPile[segmentIdx] = append(g.drawPile[segmentIdx], stone)
                }
            }
        }
    }
    g._trimEmptySegments()
+   g.debugValidateDrawPile()
 }
 
 
 // _trimEmptySegments removes empty segments from the end of the draw pile.
 func (g *PuzzleGame) _trimEmptySegments() {
-   g.numActiveSegments = MaxDrawPileSegments
+   g.numActiveSegments = g.numSegments
    for g.numActiveSegments > 0 && len(g.drawPile[g.numActiveSegments-1]) == 0 {
        g.numActiveSegments--
    }
 }
 
 
+// ValueCounts returns how many stones of each value (index 1-13) remain in the pyramid.
+// With includeDrawAndHold set, stones in the draw pile and HOLD are counted too.
+func (g *PuzzleGame) ValueCounts(includeDrawAndHold bool) [14]int {
+	var counts [14]int
+	count := func(stone int) {
+		if stone >= 1 && stone <= 13 {
+			counts[stone]++
+		}
+	}
+	for rowIdx := 0; rowIdx < MaxPyramidRows; rowIdx++ {
+		for colIdx := 0; colIdx < utils.PyramidRowSizes[rowIdx]; colIdx++ {
+			count(g.pyramid[rowIdx][colIdx])
+		}
+	}
+	if includeDrawAndHold {
+		count(g.hold)
+		for _, segment := range g.drawPile {
+			for _, stone := range segment {
+				count(stone)
+			}
+		}
+	}
+	return counts
+}
+
+
+// CanClearValue reports whether a stone of value v could still be cleared: 13s can always be
+// smashed, and other values need at least one partner left in the pyramid, HOLD or draw pile.
+// It only looks for a partner; whether a stone of value v remains is not checked. A false
+// result for a value still in the pyramid means the board can no longer be solved.
+func (g *PuzzleGame) CanClearValue(v int) bool {
+	if v == 13 {
+		return true
+	}
+	partner := matchPartner(v)
+	if partner == -1 {
+		return false
+	}
+	return g.ValueCounts(true)[partner] > 0
+}
+
+
+// DeadDrawStones returns the values of the draw pile stones that can never be cleared
+// because no partner for them is left anywhere (see CanClearValue), one entry per stone in
+// draw pile order. Such stones only ever need to be drawn past.
+func (g *PuzzleGame) DeadDrawStones() []int {
+	dead := []int{}
+	for _, segment := range g.drawPile[:g.numActiveSegments] {
+		for _, stone := range segment {
+			if stone != utils.UnknownStone && !g.CanClearValue(stone) {
+				dead = append(dead, stone)
+			}
+		}
+	}
+	return dead
+}
+
+
+// StrandedStones counts the stones in the pyramid and HOLD that can never be cleared, because no
+// partner for them is left (see CanClearValue). One such stone can still be moved out of the way
+// into HOLD for good; two or more mean the pyramid can no longer be cleared.
+func (g *PuzzleGame) StrandedStones() int {
+	stranded := 0
+	for v, count := range g.ValueCounts(false) {
+		if count > 0 && !g.CanClearValue(v) {
+			stranded += count
+		}
+	}
+	if g.hold != -1 && !g.CanClearValue(g.hold) {
+		stranded++
+	}
+	return stranded
+}
+
+
+// StepsToLock replays moves on a copy of g and returns the index of the move after which the
+// board became provably unsolvable, with two or more StrandedStones, or -1 if it never did.
+// Moves are assumed to be legal. A board already locked before the first move reports 0.
+func (g *PuzzleGame) StepsToLock(moves []Move) int {
+	replayed := g.DeepCopy()
+	for i, move := range moves {
+		replayed.MakeMove(move.Source, move.Destination)
+		if replayed.StrandedStones() >= 2 {
+			return i
+		}
+	}
+	return -1
+}
+
+
+// matchPartner returns the value that matches v under IsMatchingPair, or -1 if none does.
+func matchPartner(v int) int {
+	var g PuzzleGame
+	for partner := 1; partner <= 12; partner++ {
+		if g.IsMatchingPair(v, partner) {
+			return partner
+		}
+	}
+	return -1
+}
+
+// HoldValueEstimate is a heuristic for how worthwhile it is to park the stone at pos in the
+// empty HOLD, from how soon its partner could be played against it: 1/(1+d), where d is the
+// fewer of the draws until a partner shows as DRW1 (within the current pass) and the stones
+// still blocking the nearest partner in the pyramid, so 1 when a partner is playable at once.
+// It returns 0 if the stone has no partner left, is a 13 (smash it instead), or cannot be
+// parked because pos is not accessible or HOLD is occupied. It is only an estimate: it ignores
+// everything else the stones in the way are needed for.
+func (g *PuzzleGame) HoldValueEstimate(pos string) float64 {
+	if g.hold != -1 {
+		return 0
+	}
+	row, col, err := utils.StringToIndices(pos)
+	if err != nil || !g.IsAccessible(row, col) {
+		return 0
+	}
+	partner := matchPartner(g.pyramid[row][col])
+	if partner == -1 {
+		return 0
+	}
+	parked := g.DeepCopy()
+	parked.MakeMove(pos, "HOLD")
+
+	distance := -1
+	closer := func(d int) {
+		if distance == -1 || d < distance {
+			distance = d
+		}
+	}
+	for rowIdx := 0; rowIdx < MaxPyramidRows; rowIdx++ {
+		for colIdx := 0; colIdx < utils.PyramidRowSizes[rowIdx]; colIdx++ {
+			if parked.pyramid[rowIdx][colIdx] == partner {
+				closer(parked.BlockingDepth(rowIdx, colIdx))
+			}
+		}
+	}
+	if draws := parked.drawsUntil(partner); draws != -1 {
+		closer(draws)
+	}
+
+	if distance == -1 {
+		return 0
+	}
+	return 1 / float64(1+distance)
+}
+
+
+// UnknownDrawStones returns how many draw pile stones were set up as utils.UnknownStone.
+func (g *PuzzleGame) UnknownDrawStones() int {
+	unknown := 0
+	for _, segment := range g.drawPile {
+		for _, stone := range segment {
+			if stone == utils.UnknownStone {
+				unknown++
+			}
+		}
+	}
+	return unknown
+}
+
+
+// SampleUnknownDrawStones replaces every unknown draw pile stone with a plausible value: one
+// drawn at random, without replacement, from the stones of a standard deck (four of each value)
+// not already seen in the pyramid, HOLD or draw pile. Once those run out, for puzzles that are
+// not from a standard deck, values are drawn uniformly from 1 to 13.
+func (g *PuzzleGame) SampleUnknownDrawStones(r *rand.Rand) {
+	counts := g.ValueCounts(true)
+	unseen := []int{}
+	for v := 1; v <= 13; v++ {
+		for n := counts[v]; n < 4; n++ {
+			unseen = append(unseen, v)
+		}
+	}
+	for segmentIdx, segment := range g.drawPile {
+		for stoneIdx, stone := range segment {
+			if stone != utils.UnknownStone {
+				continue
+			}
+			if len(unseen) == 0 {
+				g.drawPile[segmentIdx][stoneIdx] = 1 + r.Intn(13)
+				continue
+			}
+			pick := r.Intn(len(unseen))
+			g.drawPile[segmentIdx][stoneIdx] = unseen[pick]
+			unseen[pick] = unseen[len(unseen)-1]
+			unseen = unseen[:len(unseen)-1]
+		}
+	}
+}
+
+
+// drawsUntil returns how many draws it takes until a stone of value v shows as DRW1, 0 if one
+// already does, or -1 if none does before the end of the current pass through the draw pile; a
+// redraw costs more than most uses of the stone are worth. g is not modified.
+func (g *PuzzleGame) drawsUntil(v int) int {
+	drawn := g.DeepCopy()
+	for draws := 0; drawn.redraws == g.redraws; draws++ {
+		if drawn.GetCurrentDrawStone() == v {
+			return draws
+		}
+		drawn.MakeMove("DRAW", "DRAW")
+	}
+	return -1
+}
+
+
+// IsHoldStuck reports whether the stone in HOLD is tying the slot up: no partner for it is
+// accessible in the pyramid or will show as DRW1 before the end of the current pass (see
+// drawsUntil), and, under the hold placement variant, it cannot be placed back in the pyramid
+// either. It returns false when HOLD is empty.
+func (g *PuzzleGame) IsHoldStuck() bool {
+	if g.hold == -1 {
+		return false
+	}
+	if g.allowHoldPlacement {
+		for rowIdx := 0; rowIdx < MaxPyramidRows; rowIdx++ {
+			for colIdx := 0; colIdx < utils.PyramidRowSizes[rowIdx]; colIdx++ {
+				if g.canPlaceHold(rowIdx, colIdx) {
+					return false
+				}
+			}
+		}
+	}
+	partner := matchPartner(g.hold)
+	if partner == -1 {
+		return true
+	}
+	for _, pos := range g.GetAccessiblePositions() {
+		if g.LocationValue(pos) == partner {
+			return false
+		}
+	}
+	return g.drawsUntil(partner) == -1
+}
+
+
+// DrawStonesRemaining returns the number of stones left in the active draw pile segments.
+func (g *PuzzleGame) DrawStonesRemaining() int {
+	remaining := 0
+	for _, segment := range g.drawPile[:g.numActiveSegments] {
+		remaining += len(segment)
+	}
+	return remaining
+}
+
+
 // IsSolved checks if the puzzle is solved (pyramid is empty).
 func (g *PuzzleGame) IsSolved() bool {
    for rowIdx := 0; rowIdx < MaxPyramidRows; rowIdx++ {
@@ -463,35 +970,56 @@ func (g *PuzzleGame) IsSolved() bool {
 
 // CalculateScore calculates the current score.
 func (g *PuzzleGame) CalculateScore() int {
-   matchingScore := g.matches * 50
-
-
-   stonesRemainingScore := 0
-   if g.IsSolved() {
-       stonesRemaining := 0
-       if g.hold != -1 {
-           stonesRemaining++
-       }
-       for _, segment := range g.drawPile {
-           stonesRemaining += len(segment)
-       }
-       stonesRemainingScore = stonesRemaining * 50
-   }
+	return g.ScoreBreakdown().Total
+}
 
+// ScoreBreakdown itemizes a score. Total is the sum of the other fields, floored at 0; it is
+// what CalculateScore returns.
+type ScoreBreakdown struct {
+	Matches    int // 50 per match or smash
+	Leftover   int // Per stone left in the draw pile and HOLD, once solved; see ScoringRules
+	Redraws    int // -50 per trip through the draw pile
+	Streak     int // Streak bonuses earned so far
+	Completion int // 500 once solved
+	TimeBonus  int // Time remaining x completion x 6
+	Total      int
+	Solved     bool // Whether the pyramid is cleared; Leftover and Completion are 0 until it is
+}
 
-   redrawCost := g.redraws * -50
-   completionBonus := 0
-   if g.IsSolved() {
-       completionBonus = 500
-   }
+// ScoreBreakdown returns the current score split into its components.
+func (g *PuzzleGame) ScoreBreakdown() ScoreBreakdown {
+	b := ScoreBreakdown{
+		Matches: g.matches * 50,
+		Redraws: g.redraws * -50,
+		Streak:  g.streakBonus,
+	}
+	b.Solved = g.IsSolved()
+	if b.Solved {
+		b.Leftover = g.DrawStonesRemaining() * g.scoringRules.LeftoverDrawStone
+		if g.hold != -1 {
+			b.Leftover += g.scoringRules.LeftoverHoldStone
+		}
+		b.Completion = 500
+	}
+	b.TimeBonus = int(math.Floor(float64(g.timeRemaining) * g.calculateCompletionPercentage() * 6))
 
+	total := b.Matches + b.Leftover + b.Redraws + b.Streak + b.Completion + b.TimeBonus
+	b.Total = int(math.Max(0, float64(total)))
+	return b
+}
 
-   completionPercentage := g.calculateCompletionPercentage()
-   timeBonus := int(math.Floor(float64(g.timeRemaining) * completionPercentage * 6))
 
+// CompletionPercentage returns the fraction of the pyramid cleared, from 0.0 (untouched) to
+// 1.0 (solved).
+func (g *PuzzleGame) CompletionPercentage() float64 {
+	return g.calculateCompletionPercentage()
+}
 
-   totalScore := matchingScore + stonesRemainingScore + redrawCost + g.streakBonus + completionBonus + timeBonus
-   return int(math.Max(0, float64(totalScore)))
+// CurrentStanding returns the score if the game ended now, whether the pyramid is cleared, and
+// the fraction of it cleared, for a running scoreboard.
+func (g *PuzzleGame) CurrentStanding() (score int, solved bool, completion float64) {
+	b := g.ScoreBreakdown()
+	return b.Total, b.Solved, g.calculateCompletionPercentage()
 }
 
 
@@ -509,6 +1037,92 @@ func (g *PuzzleGame) calculateCompletionPercentage() float64 {
 }
 
 
+// MaxPossibleScore returns an optimistic upper bound on the final score reachable from the
+// current state. It assumes every remaining stone is cleared in its own match with the streak
+// never broken, that every draw/hold stone is still left over for the solved bonus, and that the
+// full time bonus is earned. No real game can reach all of these at once, so treat the result as
+// a ceiling for comparison rather than a target.
+func (g *PuzzleGame) MaxPossibleScore() int {
+	pyramidStones := 0
+	for rowIdx := 0; rowIdx < MaxPyramidRows; rowIdx++ {
+		for colIdx := 0; colIdx < utils.PyramidRowSizes[rowIdx]; colIdx++ {
+			if g.pyramid[rowIdx][colIdx] != -1 {
+				pyramidStones++
+			}
+		}
+	}
+
+	drawStones, holdStones, spareThirteens := 0, 0, 0
+	if g.hold != -1 {
+		holdStones++
+		if g.hold == 13 {
+			spareThirteens++
+		}
+	}
+	for _, segment := range g.drawPile {
+		for _, stone := range segment {
+			drawStones++
+			if stone == 13 {
+				spareThirteens++
+			}
+		}
+	}
+	spareStones := drawStones + holdStones
+
+	// Every future clear removes at least one pyramid stone, a draw/hold 13, or a draw/hold pair.
+	futureMatches := pyramidStones + spareThirteens + (spareStones-spareThirteens)/2
+
+	streakBonus := g.streakBonus
+	for i := 1; i <= futureMatches; i++ {
+		streakBonus += streakBonusFor(g.streak + i)
+	}
+
+	leftover := drawStones*g.scoringRules.LeftoverDrawStone + holdStones*g.scoringRules.LeftoverHoldStone
+	totalScore := (g.matches+futureMatches)*50 + streakBonus + leftover - g.redraws*50 + 500 + g.timeRemaining*6
+	return int(math.Max(0, float64(totalScore)))
+}
+
+
+// ClassifyScore labels a result relative to MaxPossibleScore. Since that bound is optimistic,
+// even very strong clears land well short of it, and the bands below are set accordingly.
+// A board that was not cleared is never rated above "Partial".
+func ClassifyScore(score, maxPossible int, solved bool) string {
+	ratio := 0.0
+	if maxPossible > 0 {
+		ratio = float64(score) / float64(maxPossible)
+	}
+	switch {
+	case !solved && ratio < 0.15:
+		return "Unsolved"
+	case !solved:
+		return "Partial"
+	case ratio >= 0.55:
+		return "Perfect"
+	case ratio >= 0.4:
+		return "Great"
+	default:
+		return "Good"
+	}
+}
+
+
+// streakBonusFor returns the bonus awarded for the match that brings the streak to streak.
+// The bonus is paid per match and never resets the running total:
+//
+//	streak:  1   2    3    4    5    6    7 ...
+//	bonus:   0  50  100  150  200  200  200 ...
+//	total:   0  50  150  300  500  700  900 ...
+func streakBonusFor(streak int) int {
+	if streak < 2 {
+		return 0
+	}
+	if streak >= 5 {
+		return 200
+	}
+	return (streak - 1) * 50
+}
+
+
 // PrintState prints the current game state.
 func (g *PuzzleGame) PrintState() {
    fmt.Println("\nPyramid:")
@@ -566,7 +1180,7 @@ func (g *PuzzleGame) GetHoldValue() interface{} {
 // DeepCopy creates a new PuzzleGame instance with the same state as the original.
 // This is crucial for running independent simulations in parallel.
 func (g *PuzzleGame) DeepCopy() *PuzzleGame {
-	newGame := NewPuzzleGame() // Start with a fresh game object
+	newGame := NewPuzzleGameWithDrawLayout(g.numSegments, g.stonesPerSegment) // Start with a fresh game object
 
 	// Copy simple fields
 	newGame.hold = g.hold
@@ -577,6 +1191,11 @@ func (g *PuzzleGame) DeepCopy() *PuzzleGame {
 	newGame.redraws = g.redraws
 	newGame.timeRemaining = g.timeRemaining
 	newGame.numActiveSegments = g.numActiveSegments
+	newGame.streakPolicy = g.streakPolicy
+	newGame.scoringRules = g.scoringRules
+	newGame.allowHoldPlacement = g.allowHoldPlacement
+	newGame.initialPuzzle = g.initialPuzzle
+	newGame.initialHash = g.initialHash
 
 	// Deep copy the pyramid (array of arrays)
 	for i := range g.pyramid {
@@ -612,6 +1231,11 @@ func (g *PuzzleGame) Reset(original *PuzzleGame) {
 	g.redraws = original.redraws
 	g.timeRemaining = original.timeRemaining
 	g.numActiveSegments = original.numActiveSegments
+	g.streakPolicy = original.streakPolicy
+	g.scoringRules = original.scoringRules
+	g.allowHoldPlacement = original.allowHoldPlacement
+	g.initialPuzzle = original.initialPuzzle
+	g.initialHash = original.initialHash
 
 	// Reset the moves slice
 	g.moves = g.moves[:0] // Efficiently clear the slice while retaining capacity
@@ -622,6 +1246,11 @@ func (g *PuzzleGame) Reset(original *PuzzleGame) {
 	}
 
 	// Copy the draw pile state
+	if len(g.drawPile) != len(original.drawPile) {
+		g.drawPile = make([][]int, len(original.drawPile))
+	}
+	g.numSegments = original.numSegments
+	g.stonesPerSegment = original.stonesPerSegment
 	for i := range original.drawPile {
 		// Ensure the destination slice has enough capacity
 		if cap(g.drawPile[i]) < len(original.drawPile[i]) {
@@ -634,24 +1263,215 @@ func (g *PuzzleGame) Reset(original *PuzzleGame) {
 }
 
 
+// SetStreakResetPolicy replaces the rules for when the streak resets.
+func (g *PuzzleGame) SetStreakResetPolicy(policy StreakResetPolicy) {
+	g.streakPolicy = policy
+}
+
+
+// SetScoringRules replaces the points awarded for leftover stones.
+func (g *PuzzleGame) SetScoringRules(rules ScoringRules) {
+	g.scoringRules = rules
+}
+
+
+// SetAllowHoldPlacement enables or disables the hold placement variant, under which the held
+// stone may be put back into an empty pyramid slot with the move HOLD -> position. The slot
+// must be one a stone would be accessible in, and must not support any stone above it, so a
+// placement never buries another stone. A placement clears nothing, so for streaks it counts
+// as a non-matching move. It is off by default.
+func (g *PuzzleGame) SetAllowHoldPlacement(allowed bool) {
+	g.allowHoldPlacement = allowed
+}
+
+
+// canPlaceHold reports whether the held stone may be placed at (rowIdx, colIdx) under the
+// hold placement variant.
+func (g *PuzzleGame) canPlaceHold(rowIdx, colIdx int) bool {
+	if !g.allowHoldPlacement || g.hold == -1 {
+		return false
+	}
+	if rowIdx < 0 || rowIdx >= MaxPyramidRows || colIdx < 0 || colIdx >= utils.PyramidRowSizes[rowIdx] {
+		return false
+	}
+	if g.pyramid[rowIdx][colIdx] != -1 {
+		return false
+	}
+	if rowIdx > 0 && (g.pyramid[rowIdx-1][colIdx] != -1 || g.pyramid[rowIdx-1][colIdx+1] != -1) {
+		return false // The slot would not be accessible
+	}
+	if rowIdx+1 < MaxPyramidRows {
+		aboveSize := utils.PyramidRowSizes[rowIdx+1]
+		if colIdx-1 >= 0 && g.pyramid[rowIdx+1][colIdx-1] != -1 {
+			return false // The stone would block the one above-left
+		}
+		if colIdx < aboveSize && g.pyramid[rowIdx+1][colIdx] != -1 {
+			return false // The stone would block the one above-right
+		}
+	}
+	return true
+}
+
+
+// Hash returns a 64-bit FNV-1a hash of the game state: the board, hold, draw pile, score
+// counters and rule settings (streak policy, scoring rules and the hold placement variant).
+// The move history is not included, so two games reached by different move orders hash
+// equally when everything else matches.
+func (g *PuzzleGame) Hash() uint64 {
+	return g.hash(true)
+}
+
+
+// BoardHash is Hash without the score counters (matches, streak, redraws and so on): it
+// identifies the position of the stones alone, so returning to an earlier position hashes
+// equally even if the score has changed since.
+func (g *PuzzleGame) BoardHash() uint64 {
+	return g.hash(false)
+}
+
+
+// hash implements Hash and BoardHash.
+func (g *PuzzleGame) hash(withCounters bool) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	write := func(v int) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(int64(v)))
+		h.Write(buf[:])
+	}
+
+	for rowIdx := 0; rowIdx < MaxPyramidRows; rowIdx++ {
+		for colIdx := 0; colIdx < utils.PyramidRowSizes[rowIdx]; colIdx++ {
+			write(g.pyramid[rowIdx][colIdx])
+		}
+	}
+	write(g.hold)
+	for _, segment := range g.drawPile {
+		write(len(segment))
+		for _, stone := range segment {
+			write(stone)
+		}
+	}
+	write(g.currentSegment)
+	write(g.numActiveSegments)
+	// The rules decide which moves are legal and what they score, so games under different
+	// rules never share a hash
+	writeBool := func(b bool) {
+		if b {
+			write(1)
+		} else {
+			write(0)
+		}
+	}
+	writeBool(g.streakPolicy.ResetOnDraw)
+	writeBool(g.streakPolicy.ResetOnHoldPark)
+	writeBool(g.streakPolicy.ResetOnNonMatch)
+	write(g.scoringRules.LeftoverDrawStone)
+	write(g.scoringRules.LeftoverHoldStone)
+	writeBool(g.allowHoldPlacement)
+	if withCounters {
+		write(g.matches)
+		write(g.streak)
+		write(g.streakBonus)
+		write(g.redraws)
+		write(g.timeRemaining)
+	}
+	return h.Sum64()
+}
+
+
+// InitialPuzzleString returns the puzzle this game was set up with by SetupCustomGame, as the
+// pyramid letters and the draw pile letters on two lines, the format of a puzzle file. It is
+// unaffected by moves played since, and empty for games not set up that way.
+func (g *PuzzleGame) InitialPuzzleString() string {
+	return g.initialPuzzle
+}
+
+// Fingerprint returns a short, stable identifier for the puzzle g was set up with, e.g.
+// "a3f9c2e1", for referring to puzzles in logs and bug reports. It is the first 32 bits of the
+// Hash of the starting state, taken at setup, so moves played since do not change it. It is
+// empty for a game not set up by SetupCustomGame or SetupRandomGame.
+func (g *PuzzleGame) Fingerprint() string {
+	if g.initialHash == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%08x", g.initialHash>>32)
+}
+
+// recordInitialHash stores, for Fingerprint, the Hash of the stones just dealt into g as a fresh
+// game with the default rules would have them, so the fingerprint only depends on the puzzle.
+func (g *PuzzleGame) recordInitialHash() {
+	initial := NewPuzzleGameWithDrawLayout(g.numSegments, g.stonesPerSegment)
+	initial.pyramid = g.pyramid
+	copy(initial.drawPile, g.drawPile) // Only read by Hash
+	initial.numActiveSegments = g.numActiveSegments
+	g.initialHash = initial.Hash()
+}
+
+
 // --- Public accessors for solver ---
 func (g *PuzzleGame) PyramidValue(row, col int) int {
    return g.pyramid[row][col]
 }
+// PyramidValueSafe is PyramidValue with bounds checking against the pyramid's row sizes,
+// for callers handling untrusted coordinates. It returns an error instead of panicking.
+func (g *PuzzleGame) PyramidValueSafe(row, col int) (int, error) {
+	if row < 0 || row >= MaxPyramidRows {
+		return -1, fmt.Errorf("row index %d out of bounds: %w", row, utils.ErrOutOfRange)
+	}
+	if col < 0 || col >= utils.PyramidRowSizes[row] {
+		return -1, fmt.Errorf("column index %d out of bounds for row %d: %w", col, row, utils.ErrOutOfRange)
+	}
+	return g.pyramid[row][col], nil
+}
+
+// LocationValue returns the stone at a move location: a pyramid position, "HOLD" or "DRW1".
+// It returns -1 for an empty or unknown location.
+func (g *PuzzleGame) LocationValue(location string) int {
+	switch location {
+	case "HOLD":
+		return g.hold
+	case "DRW1":
+		return g.GetCurrentDrawStone()
+	}
+	row, col, err := utils.StringToIndices(location)
+	if err != nil {
+		return -1
+	}
+	return g.pyramid[row][col]
+}
 func (g *PuzzleGame) HoldValue() int {
    return g.hold
 }
 func (g *PuzzleGame) Redraws() int {
    return g.redraws
 }
+func (g *PuzzleGame) CurrentSegment() int {
+   return g.currentSegment
+}
 func (g *PuzzleGame) NumActiveSegments() int {
    return g.numActiveSegments
 }
 
 
-// DrawPile returns the entire drawPile array (array of slices).
-func (g *PuzzleGame) DrawPile() [MaxDrawPileSegments][]int {
-   return g.drawPile
+// Grid returns a copy of the pyramid as a jagged slice shaped like utils.PyramidRowSizes,
+// with row 0 the bottom row (A) and -1 for cleared cells.
+func (g *PuzzleGame) Grid() [][]int {
+	grid := make([][]int, MaxPyramidRows)
+	for rowIdx := range grid {
+		grid[rowIdx] = make([]int, utils.PyramidRowSizes[rowIdx])
+		copy(grid[rowIdx], g.pyramid[rowIdx][:])
+	}
+	return grid
+}
+
+
+// DrawPile returns a copy of the draw pile, one slice per configured segment.
+func (g *PuzzleGame) DrawPile() [][]int {
+	drawPile := make([][]int, len(g.drawPile))
+	for i, segment := range g.drawPile {
+		drawPile[i] = append([]int(nil), segment...)
+	}
+	return drawPile
 }
 
 
@@ -0,0 +1,797 @@
+package game
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"pyramid_solver_go_local/utils"
+)
+
+// newTestGame sets up a game with the given pyramid (A1 to G1) and draw pile.
+func newTestGame(t testing.TB, pyramid, drawPile []int) *PuzzleGame {
+	t.Helper()
+	g := NewPuzzleGame()
+	if err := g.SetupCustomGame(pyramid, drawPile); err != nil {
+		t.Fatalf("SetupCustomGame: %v", err)
+	}
+	return g
+}
+
+// examplePyramid and exampleDrawPile are the example puzzle of main.go.
+var (
+	examplePyramid  = []int{12, 10, 11, 6, 11, 7, 12, 11, 5, 1, 4, 1, 4, 5, 10, 8, 11, 9, 7, 2, 9, 6, 2, 13, 9, 10, 12, 13}
+	exampleDrawPile = []int{6, 3, 8, 9, 3, 10, 2, 13, 6, 7, 1, 13, 12, 4, 1, 2, 3, 8, 5, 3, 5, 7, 3, 8}
+)
+
+// filled returns n copies of stone.
+func filled(n, stone int) []int {
+	stones := make([]int, n)
+	for i := range stones {
+		stones[i] = stone
+	}
+	return stones
+}
+
+// pyramidWith returns a pyramid (A1 to G1) of fill stones, except for the positions in
+// stones, e.g. {"A2": 13}.
+func pyramidWith(t testing.TB, fill int, stones map[string]int) []int {
+	t.Helper()
+	pyramid := filled(TotalPyramidStones, fill)
+	for pos, stone := range stones {
+		row, col, err := utils.StringToIndices(pos)
+		if err != nil {
+			t.Fatalf("bad position %q: %v", pos, err)
+		}
+		index := col
+		for r := 0; r < row; r++ {
+			index += utils.PyramidRowSizes[r]
+		}
+		pyramid[index] = stone
+	}
+	return pyramid
+}
+
+// smashAll clears a pyramid of 13s by smashing accessible stones until none are left.
+func smashAll(t testing.TB, g *PuzzleGame) {
+	t.Helper()
+	for !g.IsSolved() {
+		accessible := g.GetAccessiblePositions()
+		if len(accessible) == 0 {
+			t.Fatal("no accessible stones left to smash")
+		}
+		g.MakeMove(accessible[0], "SMASH")
+	}
+}
+
+// benchmarkSink keeps benchmark results alive so the copying is not optimized away.
+var benchmarkSink *PuzzleGame
+
+// benchmarkTemplate returns the example puzzle a few moves in, so the move history and draw
+// pile have something to copy.
+func benchmarkTemplate(b *testing.B) *PuzzleGame {
+	g := newTestGame(b, examplePyramid, exampleDrawPile)
+	g.MakeMove("A1", "A5")
+	g.MakeMove("DRAW", "DRAW")
+	return g
+}
+
+func BenchmarkDeepCopy(b *testing.B) {
+	template := benchmarkTemplate(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		benchmarkSink = template.DeepCopy()
+	}
+}
+
+func BenchmarkReset(b *testing.B) {
+	template := benchmarkTemplate(b)
+	g := template.DeepCopy()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.Reset(template)
+	}
+	benchmarkSink = g
+}
+
+func TestScoringRulesChangeLeftoverScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules ScoringRules
+		want  int
+	}{
+		{"default", DefaultScoringRules, 2*50 + 50},
+		{"draw stones worth more", ScoringRules{LeftoverDrawStone: 100, LeftoverHoldStone: 50}, 2*100 + 50},
+		{"hold stone worth nothing", ScoringRules{LeftoverDrawStone: 50, LeftoverHoldStone: 0}, 2 * 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGame(t, filled(TotalPyramidStones, 13), []int{1, 2, 3})
+			g.SetScoringRules(tt.rules)
+			g.MakeMove("DRW1", "HOLD") // Leaves two stones in the draw pile and one in HOLD
+			smashAll(t, g)
+
+			if got := g.ScoreBreakdown().Leftover; got != tt.want {
+				t.Errorf("Leftover = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHashIncludesRules(t *testing.T) {
+	base := newTestGame(t, examplePyramid, exampleDrawPile)
+
+	scoring := base.DeepCopy()
+	scoring.SetScoringRules(ScoringRules{LeftoverDrawStone: 10, LeftoverHoldStone: 10})
+	streak := base.DeepCopy()
+	streak.SetStreakResetPolicy(StreakResetPolicy{ResetOnDraw: true, ResetOnHoldPark: true, ResetOnNonMatch: true})
+	variant := base.DeepCopy()
+	variant.SetAllowHoldPlacement(true)
+
+	for name, g := range map[string]*PuzzleGame{"scoring rules": scoring, "streak policy": streak, "hold placement": variant} {
+		if g.Hash() == base.Hash() {
+			t.Errorf("changing the %s leaves Hash unchanged", name)
+		}
+		if g.BoardHash() == base.BoardHash() {
+			t.Errorf("changing the %s leaves BoardHash unchanged", name)
+		}
+	}
+	if base.DeepCopy().Hash() != base.Hash() {
+		t.Error("a copy under the same rules hashes differently")
+	}
+}
+
+// playRandomly plays up to maxMoves random legal moves from g, calling visit after each.
+func playRandomly(g *PuzzleGame, r *rand.Rand, maxMoves int, visit func(*PuzzleGame)) {
+	for i := 0; i < maxMoves && !g.IsSolved(); i++ {
+		moves := g.LegalMoves()
+		move := moves[r.Intn(len(moves))]
+		g.MakeMove(move.Source, move.Destination)
+		visit(g)
+	}
+}
+
+func TestMaxPossibleScoreIsUpperBound(t *testing.T) {
+	solved := newTestGame(t, filled(TotalPyramidStones, 13), exampleDrawPile)
+	maxScore := solved.MaxPossibleScore()
+	smashAll(t, solved)
+	if score := solved.CalculateScore(); score > maxScore {
+		t.Errorf("solved game scored %d, above MaxPossibleScore %d", score, maxScore)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		g := newTestGame(t, examplePyramid, exampleDrawPile)
+		initialMax := g.MaxPossibleScore()
+		playRandomly(g, r, 300, func(g *PuzzleGame) {
+			if score := g.CalculateScore(); score > initialMax || score > g.MaxPossibleScore() {
+				t.Fatalf("score %d exceeds MaxPossibleScore (initially %d, now %d)", score, initialMax, g.MaxPossibleScore())
+			}
+		})
+	}
+}
+
+func TestAccessibleThirteens(t *testing.T) {
+	pyramid := pyramidWith(t, 7, map[string]int{"A2": 13, "A5": 13, "B1": 13})
+
+	g := newTestGame(t, pyramid, []int{1})
+	if got, want := g.AccessibleThirteens(), []string{"A2", "A5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AccessibleThirteens() = %v, want %v (B1 is buried)", got, want)
+	}
+	g = newTestGame(t, pyramid, []int{13})
+	if got, want := g.AccessibleThirteens(), []string{"A2", "A5", "DRW1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AccessibleThirteens() with a 13 as DRW1 = %v, want %v", got, want)
+	}
+}
+
+func TestClassifyScore(t *testing.T) {
+	tests := []struct {
+		score, max int
+		solved     bool
+		want       string
+	}{
+		{0, 10000, false, "Unsolved"},
+		{1499, 10000, false, "Unsolved"},
+		{1500, 10000, false, "Partial"},
+		{9000, 10000, false, "Partial"}, // Never above Partial without a clear
+		{3000, 10000, true, "Good"},
+		{4000, 10000, true, "Great"},
+		{5499, 10000, true, "Great"},
+		{5500, 10000, true, "Perfect"},
+		{100, 0, false, "Unsolved"},
+		{100, 0, true, "Good"},
+	}
+	for _, tt := range tests {
+		if got := ClassifyScore(tt.score, tt.max, tt.solved); got != tt.want {
+			t.Errorf("ClassifyScore(%d, %d, %v) = %q, want %q", tt.score, tt.max, tt.solved, got, tt.want)
+		}
+	}
+}
+
+func TestGridMatchesPyramid(t *testing.T) {
+	g := newTestGame(t, examplePyramid, exampleDrawPile)
+	g.MakeMove("A1", "A5") // 12 + 11 leaves gaps in the base row
+	grid := g.Grid()
+
+	if len(grid) != MaxPyramidRows {
+		t.Fatalf("grid has %d rows, want %d", len(grid), MaxPyramidRows)
+	}
+	for rowIdx, row := range grid {
+		if len(row) != utils.PyramidRowSizes[rowIdx] {
+			t.Errorf("row %d has %d cells, want %d", rowIdx, len(row), utils.PyramidRowSizes[rowIdx])
+		}
+		for colIdx, stone := range row {
+			if want := g.PyramidValue(rowIdx, colIdx); stone != want {
+				t.Errorf("grid[%d][%d] = %d, want PyramidValue %d", rowIdx, colIdx, stone, want)
+			}
+		}
+	}
+
+	grid[1][0] = 99
+	if g.PyramidValue(1, 0) == 99 {
+		t.Error("changing the grid changed the game")
+	}
+}
+
+func TestStreakBonusTable(t *testing.T) {
+	// The table documented on streakBonusFor
+	table := []struct{ bonus, total int }{
+		{0, 0}, {50, 50}, {100, 150}, {150, 300}, {200, 500}, {200, 700}, {200, 900},
+	}
+	// Seven pairs that can be matched one after another with nothing breaking the streak
+	pyramid := pyramidWith(t, 13, map[string]int{"A1": 1, "A2": 2, "A3": 3, "A4": 4, "A5": 5, "A6": 6,
+		"A7": 7, "B1": 8, "B2": 9, "B3": 10, "B4": 11, "B5": 12, "B6": 1, "C1": 2})
+	g := newTestGame(t, pyramid, []int{5})
+	pairs := []Move{{"A1", "A2"}, {"A3", "A4"}, {"A5", "A6"}, {"A7", "B1"}, {"B2", "B3"}, {"B4", "B5"}, {"B6", "C1"}}
+
+	for i, pair := range pairs {
+		streak := i + 1
+		if got := streakBonusFor(streak); got != table[i].bonus {
+			t.Errorf("streakBonusFor(%d) = %d, want %d", streak, got, table[i].bonus)
+		}
+		if !g.IsLegalMove(pair) {
+			t.Fatalf("match %d (%v) is not legal", streak, pair)
+		}
+		g.MakeMove(pair.Source, pair.Destination)
+		if got := g.ScoreBreakdown().Streak; got != table[i].total {
+			t.Errorf("after streak %d the streak bonus is %d, want %d", streak, got, table[i].total)
+		}
+	}
+}
+
+func TestStreakResetPolicy(t *testing.T) {
+	actions := map[string]Move{
+		"draw":      {Source: "DRAW", Destination: "DRAW"},
+		"hold park": {Source: "A5", Destination: "HOLD"},
+		"non-match": {Source: "A3", Destination: "A4"},
+	}
+	tests := []struct {
+		name   string
+		policy StreakResetPolicy
+		resets map[string]bool // Which actions break the streak under policy
+	}{
+		{"default", DefaultStreakResetPolicy, map[string]bool{"draw": true, "hold park": false, "non-match": true}},
+		{"none", StreakResetPolicy{}, map[string]bool{}},
+		{"draw only", StreakResetPolicy{ResetOnDraw: true}, map[string]bool{"draw": true}},
+		{"hold park only", StreakResetPolicy{ResetOnHoldPark: true}, map[string]bool{"hold park": true}},
+		{"non-match only", StreakResetPolicy{ResetOnNonMatch: true}, map[string]bool{"non-match": true}},
+	}
+	pyramid := pyramidWith(t, 13, map[string]int{"A1": 1, "A2": 2, "A3": 5, "A4": 9, "A5": 9})
+	for _, tt := range tests {
+		for name, action := range actions {
+			g := newTestGame(t, pyramid, filled(2*StonesPerSegment, 7))
+			g.SetStreakResetPolicy(tt.policy)
+			g.MakeMove("A1", "A2")
+			g.MakeMove(action.Source, action.Destination)
+			if reset := g.streak == 0; reset != tt.resets[name] {
+				t.Errorf("%s policy: %s left the streak at %d, want reset %v", tt.name, name, g.streak, tt.resets[name])
+			}
+		}
+	}
+}
+
+func TestDrawStonesRemaining(t *testing.T) {
+	pyramid := pyramidWith(t, 13, map[string]int{"A1": 1, "A3": 3, "A4": 4})
+	g := newTestGame(t, pyramid, []int{5, 6, 2, 8})
+	if got := g.DrawStonesRemaining(); got != 4 {
+		t.Fatalf("DrawStonesRemaining() = %d at the start, want 4", got)
+	}
+	g.MakeMove("A1", "DRW1") // Matches the 1 with the 2 on top of the draw pile
+	if got := g.DrawStonesRemaining(); got != 3 {
+		t.Errorf("DrawStonesRemaining() = %d after a draw pile match, want 3", got)
+	}
+	g.MakeMove("A3", "A4") // Pyramid-only match
+	if got := g.DrawStonesRemaining(); got != 3 {
+		t.Errorf("DrawStonesRemaining() = %d after a pyramid match, want 3", got)
+	}
+}
+
+func TestDrawLayoutVariant(t *testing.T) {
+	const segments, perSegment = 6, 4
+	drawPile := make([]int, segments*perSegment)
+	for i := range drawPile {
+		drawPile[i] = i%12 + 1
+	}
+	g := NewPuzzleGameWithDrawLayout(segments, perSegment)
+	if err := g.SetupCustomGame(filled(TotalPyramidStones, 13), drawPile); err != nil {
+		t.Fatalf("SetupCustomGame: %v", err)
+	}
+	layout := g.DrawPile()
+	if len(layout) != segments {
+		t.Fatalf("draw pile has %d segments, want %d", len(layout), segments)
+	}
+	for i, segment := range layout {
+		if want := drawPile[i*perSegment : (i+1)*perSegment]; !reflect.DeepEqual(segment, want) {
+			t.Errorf("segment %d = %v, want %v", i, segment, want)
+		}
+	}
+	smashAll(t, g)
+
+	tooMany := NewPuzzleGameWithDrawLayout(segments, perSegment)
+	if err := tooMany.SetupCustomGame(filled(TotalPyramidStones, 13), append(drawPile, 1)); err == nil {
+		t.Errorf("SetupCustomGame accepted %d stones for a %dx%d draw pile", len(drawPile)+1, segments, perSegment)
+	}
+}
+
+func TestCompletionPercentage(t *testing.T) {
+	g := newTestGame(t, filled(TotalPyramidStones, 13), nil)
+	if got := g.CompletionPercentage(); got != 0 {
+		t.Errorf("CompletionPercentage() of a full pyramid = %v, want 0", got)
+	}
+	for i := 0; i < TotalPyramidStones/2; i++ {
+		g.MakeMove(g.GetAccessiblePositions()[0], "SMASH")
+	}
+	if got := g.CompletionPercentage(); got < 0.49 || got > 0.51 {
+		t.Errorf("CompletionPercentage() with half the pyramid cleared = %v, want about 0.5", got)
+	}
+	smashAll(t, g)
+	if got := g.CompletionPercentage(); got != 1 {
+		t.Errorf("CompletionPercentage() of a solved pyramid = %v, want 1", got)
+	}
+}
+
+func TestValueCounts(t *testing.T) {
+	g := newTestGame(t, examplePyramid, exampleDrawPile)
+	g.MakeMove("A1", "A5") // Clears a 12 and an 11
+
+	pyramid := g.ValueCounts(false)
+	total := 0
+	for _, n := range pyramid {
+		total += n
+	}
+	if want := TotalPyramidStones - 2; total != want {
+		t.Errorf("pyramid counts sum to %d, want the %d stones left", total, want)
+	}
+	if pyramid[13] != 2 || pyramid[12] != 2 || pyramid[11] != 3 {
+		t.Errorf("pyramid counts for 13, 12, 11 = %d, %d, %d, want 2, 2, 3", pyramid[13], pyramid[12], pyramid[11])
+	}
+
+	all := g.ValueCounts(true)
+	if all[13] != 4 {
+		t.Errorf("counts with the draw pile have %d 13s, want 4", all[13])
+	}
+	total = 0
+	for _, n := range all {
+		total += n
+	}
+	if want := TotalPyramidStones - 2 + len(exampleDrawPile); total != want {
+		t.Errorf("counts with the draw pile sum to %d, want %d", total, want)
+	}
+}
+
+func TestCurrentDrawSourceBackfills(t *testing.T) {
+	g := newTestGame(t, filled(TotalPyramidStones, 7), []int{1, 2, 3, 13, 13, 13})
+	if seg, value := g.CurrentDrawSource(); seg != 0 || value != 3 {
+		t.Fatalf("CurrentDrawSource() = %d, %d at the start, want 0, 3", seg, value)
+	}
+	g.MakeMove("DRAW", "DRAW")
+	for i := 0; i < StonesPerSegment; i++ {
+		if seg, value := g.CurrentDrawSource(); seg != 1 || value != 13 {
+			t.Fatalf("CurrentDrawSource() = %d, %d before smash %d, want 1, 13", seg, value, i+1)
+		}
+		g.MakeMove("DRW1", "SMASH")
+	}
+	if seg, value := g.CurrentDrawSource(); seg != 0 || value != 3 {
+		t.Errorf("CurrentDrawSource() = %d, %d with segment 1 used up, want the earlier segment: 0, 3", seg, value)
+	}
+}
+
+func TestPyramidValueSafe(t *testing.T) {
+	g := newTestGame(t, examplePyramid, exampleDrawPile)
+	if stone, err := g.PyramidValueSafe(6, 0); err != nil || stone != 13 {
+		t.Errorf("PyramidValueSafe(6, 0) = %d, %v, want the apex 13", stone, err)
+	}
+	for _, pos := range [][2]int{{-1, 0}, {MaxPyramidRows, 0}, {0, -1}, {0, 7}, {6, 1}, {1, 6}} {
+		if stone, err := g.PyramidValueSafe(pos[0], pos[1]); !errors.Is(err, utils.ErrOutOfRange) {
+			t.Errorf("PyramidValueSafe(%d, %d) = %d, %v, want an ErrOutOfRange error", pos[0], pos[1], stone, err)
+		}
+	}
+}
+
+func TestIsAccessibleRightEdge(t *testing.T) {
+	g := newTestGame(t, filled(TotalPyramidStones, 13), nil)
+	if !g.IsAccessible(0, utils.PyramidRowSizes[0]-1) {
+		t.Error("A7 is not accessible on a full pyramid")
+	}
+	// Clear the right edge one row at a time: each rightmost cell becomes accessible once the
+	// last two cells of the row below are gone
+	for row := 1; row < MaxPyramidRows; row++ {
+		col := utils.PyramidRowSizes[row] - 1
+		if g.IsAccessible(row, col) {
+			t.Fatalf("rightmost cell of row %d is accessible while the row below is full", row)
+		}
+		for _, below := range []int{col, col + 1} {
+			pos, err := utils.IndicesToString(row-1, below)
+			if err != nil {
+				t.Fatalf("IndicesToString(%d, %d): %v", row-1, below, err)
+			}
+			if g.PyramidValue(row-1, below) != -1 && !g.MakeMove(pos, "SMASH") {
+				t.Fatalf("could not smash %s", pos)
+			}
+		}
+		if !g.IsAccessible(row, col) {
+			t.Errorf("rightmost cell of row %d is not accessible with both cells below cleared", row)
+		}
+	}
+	for row := 0; row < MaxPyramidRows; row++ {
+		if g.IsAccessible(row, utils.PyramidRowSizes[row]) {
+			t.Errorf("IsAccessible(%d, %d) is past the end of the row but reports true", row, utils.PyramidRowSizes[row])
+		}
+	}
+}
+
+func TestSetupCustomGameErrors(t *testing.T) {
+	tests := []struct {
+		name              string
+		pyramid, drawPile []int
+		want              error
+	}{
+		{"short pyramid", examplePyramid[1:], exampleDrawPile, utils.ErrWrongLength},
+		{"long draw pile", examplePyramid, append(filled(MaxDrawPileSegments*StonesPerSegment, 1), 1), utils.ErrWrongLength},
+		{"unknown pyramid stone", filled(TotalPyramidStones, utils.UnknownStone), nil, utils.ErrOutOfRange},
+	}
+	for _, tt := range tests {
+		err := NewPuzzleGame().SetupCustomGame(tt.pyramid, tt.drawPile)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: SetupCustomGame error %v does not wrap %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestInitialPuzzleStringSurvivesMoves(t *testing.T) {
+	g := newTestGame(t, examplePyramid, exampleDrawPile)
+	want := utils.StonesToLetters(examplePyramid) + "\n" + utils.StonesToLetters(exampleDrawPile)
+	if got := g.InitialPuzzleString(); got != want {
+		t.Fatalf("InitialPuzzleString() = %q, want %q", got, want)
+	}
+	playRandomly(g, rand.New(rand.NewSource(1)), 50, func(*PuzzleGame) {})
+	if got := g.InitialPuzzleString(); got != want {
+		t.Errorf("InitialPuzzleString() after playing = %q, want %q", got, want)
+	}
+	if got := g.DeepCopy().InitialPuzzleString(); got != want {
+		t.Errorf("InitialPuzzleString() of a copy = %q, want %q", got, want)
+	}
+}
+
+func TestCanClearValue(t *testing.T) {
+	// The 1 at A1 has no 2 anywhere; the 3 at A2 has a 4 in the draw pile
+	g := newTestGame(t, pyramidWith(t, 13, map[string]int{"A1": 1, "A2": 3}), []int{7, 4})
+	tests := []struct {
+		v    int
+		want bool
+	}{
+		{1, false},
+		{3, true},
+		{13, true},
+		{8, true}, // The 7 in the draw pile partners an 8
+		{5, false},
+	}
+	for _, tt := range tests {
+		if got := g.CanClearValue(tt.v); got != tt.want {
+			t.Errorf("CanClearValue(%d) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestScoreBreakdownSolved(t *testing.T) {
+	g := newTestGame(t, filled(TotalPyramidStones, 13), []int{1, 2})
+	g.MakeMove("A1", "SMASH")
+	if b := g.ScoreBreakdown(); b.Solved || b.Completion != 0 || b.Leftover != 0 {
+		t.Errorf("partial clear breakdown = %+v, want unsolved with no completion or leftover score", b)
+	}
+	smashAll(t, g)
+	if b := g.ScoreBreakdown(); !b.Solved || b.Completion == 0 || b.Total != g.CalculateScore() {
+		t.Errorf("full clear breakdown = %+v, want solved with a completion bonus totalling %d", b, g.CalculateScore())
+	}
+}
+
+func TestPositionsTopDown(t *testing.T) {
+	positions := newTestGame(t, examplePyramid, exampleDrawPile).PositionsTopDown()
+	if len(positions) != TotalPyramidStones {
+		t.Fatalf("PositionsTopDown() has %d positions, want %d", len(positions), TotalPyramidStones)
+	}
+	if first, last := positions[0], positions[len(positions)-1]; first != "G1" || last != "A7" {
+		t.Errorf("PositionsTopDown() runs from %s to %s, want G1 to A7", first, last)
+	}
+	if positions[1] != "F1" || positions[2] != "F2" {
+		t.Errorf("PositionsTopDown() continues %s, %s after G1, want F1, F2", positions[1], positions[2])
+	}
+}
+
+func TestBlockingDepth(t *testing.T) {
+	g := newTestGame(t, filled(TotalPyramidStones, 13), nil)
+	tests := []struct {
+		pos  string
+		want int
+	}{
+		{"A1", 0},
+		{"A7", 0},
+		{"B1", 2},
+		{"C1", 5}, // B1 and B2, then A1 to A3
+		{"G1", TotalPyramidStones - 1},
+	}
+	for _, tt := range tests {
+		row, col, _ := utils.StringToIndices(tt.pos)
+		if got := g.BlockingDepth(row, col); got != tt.want {
+			t.Errorf("BlockingDepth(%s) = %d, want %d", tt.pos, got, tt.want)
+		}
+	}
+	g.MakeMove("A1", "SMASH")
+	if got := g.BlockingDepth(1, 0); got != 1 {
+		t.Errorf("BlockingDepth(B1) with A1 cleared = %d, want 1", got)
+	}
+	if got := g.BlockingDepth(0, 7); got != -1 {
+		t.Errorf("BlockingDepth(0, 7) = %d, want -1 outside the pyramid", got)
+	}
+}
+
+func TestDeadDrawStones(t *testing.T) {
+	// Nothing left partners the 5s (no 6) or the 7 (no 8); the 4 partners the 3 at A1
+	g := newTestGame(t, pyramidWith(t, 13, map[string]int{"A1": 3}), []int{5, 4, 7, 5})
+	if got, want := g.DeadDrawStones(), []int{5, 7, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DeadDrawStones() = %v, want %v", got, want)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	want := GameConfig{
+		Rows:             MaxPyramidRows,
+		PyramidStones:    TotalPyramidStones,
+		Segments:         MaxDrawPileSegments,
+		StonesPerSegment: StonesPerSegment,
+		TimeRemaining:    120,
+	}
+	if got := NewPuzzleGame().Config(); got != want {
+		t.Errorf("Config() = %+v, want %+v", got, want)
+	}
+	variant := NewPuzzleGameWithDrawLayout(6, 4).Config()
+	if variant.Segments != 6 || variant.StonesPerSegment != 4 {
+		t.Errorf("Config() of a 6x4 draw pile = %+v", variant)
+	}
+}
+
+func TestCurrentStanding(t *testing.T) {
+	g := newTestGame(t, examplePyramid, exampleDrawPile)
+	playRandomly(g, rand.New(rand.NewSource(1)), 20, func(*PuzzleGame) {})
+	score, solved, completion := g.CurrentStanding()
+	if score != g.CalculateScore() || solved != g.IsSolved() || completion != g.CompletionPercentage() {
+		t.Errorf("CurrentStanding() = %d, %v, %v, want %d, %v, %v",
+			score, solved, completion, g.CalculateScore(), g.IsSolved(), g.CompletionPercentage())
+	}
+}
+
+func TestHoldValueEstimate(t *testing.T) {
+	pyramid := pyramidWith(t, 7, map[string]int{"A1": 1})
+	estimate := func(drawPile []int) float64 {
+		return newTestGame(t, pyramid, drawPile).HoldValueEstimate("A1")
+	}
+
+	soon := estimate([]int{9, 9, 2})           // The 2 is DRW1 already
+	later := estimate([]int{9, 9, 9, 9, 9, 2}) // The 2 shows after a draw
+	never := estimate([]int{9, 9, 9, 9, 9, 9}) // No 2 anywhere
+	if soon != 1 {
+		t.Errorf("estimate with the partner as DRW1 = %v, want 1", soon)
+	}
+	if later <= 0 || later >= soon {
+		t.Errorf("estimate with the partner a draw away = %v, want between 0 and %v", later, soon)
+	}
+	if never != 0 {
+		t.Errorf("estimate with no partner left = %v, want 0", never)
+	}
+	if got := newTestGame(t, pyramid, []int{2}).HoldValueEstimate("B1"); got != 0 {
+		t.Errorf("estimate for the buried B1 = %v, want 0", got)
+	}
+}
+
+func TestPlanSmashOrder(t *testing.T) {
+	pyramid := pyramidWith(t, 7, map[string]int{"A1": 13, "A2": 13, "A3": 1, "A4": 2, "A7": 13})
+	g := newTestGame(t, pyramid, []int{13})
+	g.MakeMove("A3", "A4")
+	// Smashing A2 uncovers B2 at once, after which A1 uncovers B1; A7 uncovers nothing, and
+	// the 13 on DRW1 is never part of the plan
+	if got, want := g.PlanSmashOrder(), []string{"A2", "A1", "A7"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PlanSmashOrder() = %v, want %v", got, want)
+	}
+	if g.PyramidValue(0, 0) != 13 {
+		t.Error("PlanSmashOrder modified the game")
+	}
+}
+
+func TestMatchChains(t *testing.T) {
+	deep := newTestGame(t, examplePyramid, exampleDrawPile)
+	if got := deep.MatchChains(); got != 64 {
+		t.Errorf("MatchChains() of a full pyramid = %d, want 64", got)
+	}
+
+	// Only row A left, as if everything above had been cleared
+	shallow := newTestGame(t, examplePyramid, exampleDrawPile)
+	for rowIdx := 1; rowIdx < MaxPyramidRows; rowIdx++ {
+		for colIdx := 0; colIdx < utils.PyramidRowSizes[rowIdx]; colIdx++ {
+			shallow.pyramid[rowIdx][colIdx] = -1
+		}
+	}
+	if got := shallow.MatchChains(); got != utils.PyramidRowSizes[0] {
+		t.Errorf("MatchChains() of row A alone = %d, want one chain per stone: %d", got, utils.PyramidRowSizes[0])
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	g := newTestGame(t, examplePyramid, exampleDrawPile)
+	fingerprint := g.Fingerprint()
+	if len(fingerprint) != 8 {
+		t.Errorf("Fingerprint() = %q, want 8 hex digits", fingerprint)
+	}
+	if again := newTestGame(t, examplePyramid, exampleDrawPile).Fingerprint(); again != fingerprint {
+		t.Errorf("the same puzzle fingerprints as %q and %q", fingerprint, again)
+	}
+	g.MakeMove("A1", "A5")
+	if played := g.Fingerprint(); played != fingerprint {
+		t.Errorf("Fingerprint() changed from %q to %q after a move", fingerprint, played)
+	}
+
+	modified := append([]int{}, exampleDrawPile...)
+	modified[0] = 13 - modified[0]
+	if other := newTestGame(t, examplePyramid, modified).Fingerprint(); other == fingerprint {
+		t.Errorf("a puzzle with a different draw pile has the same fingerprint %q", other)
+	}
+
+	if unset := NewPuzzleGame().Fingerprint(); unset != "" {
+		t.Errorf("Fingerprint() of a game never set up = %q, want empty", unset)
+	}
+	random := NewPuzzleGame()
+	random.SetupRandomGame()
+	dealt := random.Fingerprint()
+	random.MakeMove("DRAW", "DRAW")
+	if dealt == "" || random.Fingerprint() != dealt {
+		t.Errorf("random game fingerprint went from %q to %q after a move", dealt, random.Fingerprint())
+	}
+}
+
+func TestSampleUnknownDrawStones(t *testing.T) {
+	drawPile := append([]int{}, exampleDrawPile...)
+	unknownAt := []int{0, 5, 17}
+	for _, i := range unknownAt {
+		drawPile[i] = utils.UnknownStone
+	}
+	unsampled := append([]int{}, drawPile...)
+	known := newTestGame(t, examplePyramid, drawPile).ValueCounts(true)
+	r := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 20; trial++ {
+		g := newTestGame(t, examplePyramid, drawPile)
+		if got := g.UnknownDrawStones(); got != len(unknownAt) {
+			t.Fatalf("UnknownDrawStones() = %d, want %d", got, len(unknownAt))
+		}
+		g.SampleUnknownDrawStones(r)
+		if got := g.UnknownDrawStones(); got != 0 {
+			t.Fatalf("%d unknown stones left after sampling", got)
+		}
+		// The example already holds five 3s, so compare against the known stones
+		for v, n := range g.ValueCounts(true) {
+			if n > max(4, known[v]) {
+				t.Errorf("sampling made %d stones of value %d, more than a deck holds", n, v)
+			}
+		}
+		if !reflect.DeepEqual(drawPile, unsampled) {
+			t.Fatalf("sampling changed the slice the game was set up from")
+		}
+		if err := g.Invariants(); err != nil {
+			t.Errorf("sampled game breaks an invariant: %v", err)
+		}
+	}
+}
+
+func TestStepsToLock(t *testing.T) {
+	// Three 1s and a single 2 on DRW1: matching a 1 with it leaves the other two stranded
+	g := newTestGame(t, pyramidWith(t, 13, map[string]int{"A1": 1, "A3": 1, "A5": 1}), []int{2})
+	locking := []Move{{"A2", "SMASH"}, {"A1", "DRW1"}, {"A4", "SMASH"}}
+	if got := g.StepsToLock(locking); got != 1 {
+		t.Errorf("StepsToLock(%s) = %d, want 1", EncodeMoves(locking), got)
+	}
+	safe := []Move{{"A2", "SMASH"}, {"A4", "SMASH"}}
+	if got := g.StepsToLock(safe); got != -1 {
+		t.Errorf("StepsToLock(%s) = %d, want -1", EncodeMoves(safe), got)
+	}
+	if g.GetCurrentDrawStone() != 2 {
+		t.Error("StepsToLock modified the game")
+	}
+
+	locked := newTestGame(t, pyramidWith(t, 13, map[string]int{"A1": 1, "A3": 1}), []int{7})
+	if got := locked.StepsToLock([]Move{{"A2", "SMASH"}}); got != 0 {
+		t.Errorf("StepsToLock on a board already locked = %d, want 0", got)
+	}
+}
+
+func TestRemainingCentroid(t *testing.T) {
+	g := newTestGame(t, filled(TotalPyramidStones, 13), nil)
+	row, col := g.RemainingCentroid()
+	if col != 3 || row <= 0 {
+		t.Errorf("RemainingCentroid() of a full pyramid = %v, %v, want col 3 and a row above A", row, col)
+	}
+	for _, pos := range []string{"A1", "A2", "B1"} {
+		g.MakeMove(pos, "SMASH")
+	}
+	if _, shifted := g.RemainingCentroid(); shifted <= col {
+		t.Errorf("col = %v after clearing the left side, want above %v", shifted, col)
+	}
+	smashAll(t, g)
+	if row, col := g.RemainingCentroid(); row != -1 || col != -1 {
+		t.Errorf("RemainingCentroid() of an empty pyramid = %v, %v, want -1, -1", row, col)
+	}
+}
+
+func TestIsHoldStuck(t *testing.T) {
+	tests := []struct {
+		name     string
+		pyramid  map[string]int
+		drawPile []int // The 1 on top of the first segment is parked in HOLD
+		want     bool
+	}{
+		{"partner buried", map[string]int{"B1": 2}, []int{9, 9, 1}, true},
+		{"partner accessible", map[string]int{"A3": 2}, []int{9, 9, 1}, false},
+		{"partner a draw away", map[string]int{}, []int{9, 9, 1, 9, 9, 2}, false},
+		{"no partner at all", map[string]int{}, []int{9, 9, 1}, true},
+	}
+	for _, tt := range tests {
+		g := newTestGame(t, pyramidWith(t, 7, tt.pyramid), tt.drawPile)
+		if g.IsHoldStuck() {
+			t.Errorf("%s: IsHoldStuck() with HOLD empty = true", tt.name)
+		}
+		g.MakeMove("DRW1", "HOLD")
+		if got := g.IsHoldStuck(); got != tt.want {
+			t.Errorf("%s: IsHoldStuck() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDrawOrderMatchesConsumption(t *testing.T) {
+	g := newTestGame(t, filled(TotalPyramidStones, 7), []int{1, 2, 3, 4, 5, 6, 8, 9})
+	g.MakeMove("DRAW", "DRAW")
+	want := g.DrawOrder()
+
+	// Use up each DRW1 in place, drawing only once nothing is left to backfill from
+	got := []int{}
+	for {
+		seg, stone := g.CurrentDrawSource()
+		if seg == -1 {
+			if g.currentSegment+1 >= g.numActiveSegments {
+				break
+			}
+			g.MakeMove("DRAW", "DRAW")
+			continue
+		}
+		if stone != g.GetCurrentDrawStone() {
+			t.Fatalf("GetCurrentDrawStone() = %d, CurrentDrawSource gives %d", g.GetCurrentDrawStone(), stone)
+		}
+		got = append(got, stone)
+		g.drawPile[seg] = g.drawPile[seg][:len(g.drawPile[seg])-1]
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DrawOrder() = %v, stones were used up as %v", want, got)
+	}
+	if wantOrder := []int{6, 5, 4, 3, 2, 1, 9, 8}; !reflect.DeepEqual(want, wantOrder) {
+		t.Errorf("DrawOrder() = %v, want %v", want, wantOrder)
+	}
+}
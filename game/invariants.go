@@ -0,0 +1,71 @@
+package game
+
+import (
+	"fmt"
+
+	"pyramid_solver_go_local/utils"
+)
+
+// Invariants checks that the game state is internally consistent: every stone is a value from
+// 1 to 13, or utils.UnknownStone outside the pyramid, and the draw pile respects its layout. It
+// returns the first violation found. Games built through this package always pass; the check is
+// for loaders and debugging.
+func (g *PuzzleGame) Invariants() error {
+	for rowIdx := 0; rowIdx < MaxPyramidRows; rowIdx++ {
+		for colIdx := 0; colIdx < utils.PyramidRowSizes[rowIdx]; colIdx++ {
+			if stone := g.pyramid[rowIdx][colIdx]; stone != -1 && (stone < 1 || stone > 13) {
+				pos, _ := utils.IndicesToString(rowIdx, colIdx)
+				return fmt.Errorf("%w: %d at %s", utils.ErrOutOfRange, stone, pos)
+			}
+		}
+	}
+	if g.hold != -1 && g.hold != utils.UnknownStone && (g.hold < 1 || g.hold > 13) {
+		return fmt.Errorf("%w: %d in HOLD", utils.ErrOutOfRange, g.hold)
+	}
+	return g.validateDrawPile()
+}
+
+// validateDrawPile checks the draw pile against its layout: numSegments segments of at most
+// stonesPerSegment stones each, numActiveSegments covering every non-empty segment, and a
+// valid current segment.
+func (g *PuzzleGame) validateDrawPile() error {
+	if len(g.drawPile) != g.numSegments {
+		return fmt.Errorf("draw pile has %d segments, want %d", len(g.drawPile), g.numSegments)
+	}
+	lastNonEmpty := -1
+	for segmentIdx, segment := range g.drawPile {
+		if len(segment) > g.stonesPerSegment {
+			return fmt.Errorf("draw segment %d holds %d stones, more than %d", segmentIdx, len(segment), g.stonesPerSegment)
+		}
+		for _, stone := range segment {
+			if stone != utils.UnknownStone && (stone < 1 || stone > 13) {
+				return fmt.Errorf("%w: %d in draw segment %d", utils.ErrOutOfRange, stone, segmentIdx)
+			}
+		}
+		if len(segment) > 0 {
+			lastNonEmpty = segmentIdx
+		}
+	}
+	// Segments emptied by play are only trimmed at certain points, so trailing empty active
+	// segments are fine; a stone outside the active ones is not
+	if g.numActiveSegments <= lastNonEmpty || g.numActiveSegments > g.numSegments {
+		return fmt.Errorf("%d active draw segments recorded, but the last non-empty segment is %d", g.numActiveSegments, lastNonEmpty)
+	}
+	// The current segment may lie past the active ones once they have been emptied; draws
+	// then backfill from earlier segments
+	if g.currentSegment < 0 || g.currentSegment >= g.numSegments {
+		return fmt.Errorf("current draw segment %d is outside the %d segments", g.currentSegment, g.numSegments)
+	}
+	return nil
+}
+
+// debugValidateDrawPile panics if the draw pile is inconsistent, in builds with the
+// pyramid_debug tag. Otherwise debugChecks is false and it compiles to nothing.
+func (g *PuzzleGame) debugValidateDrawPile() {
+	if !debugChecks {
+		return
+	}
+	if err := g.validateDrawPile(); err != nil {
+		panic(err)
+	}
+}
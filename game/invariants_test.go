@@ -0,0 +1,46 @@
+package game
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"pyramid_solver_go_local/utils"
+)
+
+func TestInvariantsHoldDuringPlay(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		g := newTestGame(t, examplePyramid, exampleDrawPile)
+		playRandomly(g, r, 300, func(g *PuzzleGame) {
+			if err := g.Invariants(); err != nil {
+				t.Fatalf("Invariants() after %s: %v", EncodeMoves(g.moves), err)
+			}
+		})
+	}
+}
+
+func TestInvariantsCatchCorruption(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(g *PuzzleGame)
+		want    error // nil when any error will do
+	}{
+		{"pyramid stone out of range", func(g *PuzzleGame) { g.pyramid[2][1] = 14 }, utils.ErrOutOfRange},
+		{"hold stone out of range", func(g *PuzzleGame) { g.hold = 20 }, utils.ErrOutOfRange},
+		{"draw stone out of range", func(g *PuzzleGame) { g.drawPile[0][0] = -5 }, utils.ErrOutOfRange},
+		{"overfull segment", func(g *PuzzleGame) { g.drawPile[1] = append(g.drawPile[1], 1) }, nil},
+		{"stone past the active segments", func(g *PuzzleGame) { g.numActiveSegments = 1 }, nil},
+		{"current segment out of range", func(g *PuzzleGame) { g.currentSegment = g.numSegments }, nil},
+	}
+	for _, tt := range tests {
+		g := newTestGame(t, examplePyramid, exampleDrawPile)
+		// Own the draw pile slices before corrupting them
+		g.drawPile = g.DrawPile()
+		tt.corrupt(g)
+		err := g.Invariants()
+		if err == nil || (tt.want != nil && !errors.Is(err, tt.want)) {
+			t.Errorf("%s: Invariants() = %v, want an error wrapping %v", tt.name, err, tt.want)
+		}
+	}
+}
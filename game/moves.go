@@ -0,0 +1,337 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"pyramid_solver_go_local/utils"
+)
+
+// LegalMoves lists every legal move in the current state, starting with DRAW unless the draw
+// pile is used up, when drawing would only cost a redraw. Pyramid pairs appear once, with the
+// position that comes first in GetAccessiblePositions as the source.
+func (g *PuzzleGame) LegalMoves() []Move {
+	moves := []Move{}
+	if g.DrawStonesRemaining() > 0 {
+		moves = append(moves, Move{Source: "DRAW", Destination: "DRAW"})
+	}
+	accessiblePositions := g.GetAccessiblePositions()
+	for _, pos := range accessiblePositions {
+		row, col, _ := utils.StringToIndices(pos)
+		if g.PyramidValue(row, col) == 13 {
+			moves = append(moves, Move{Source: pos, Destination: "SMASH"})
+		}
+	}
+	for i, pos1 := range accessiblePositions {
+		row1, col1, _ := utils.StringToIndices(pos1)
+		stone1 := g.PyramidValue(row1, col1)
+		if stone1 == 13 {
+			continue
+		}
+		for j := i + 1; j < len(accessiblePositions); j++ {
+			pos2 := accessiblePositions[j]
+			row2, col2, _ := utils.StringToIndices(pos2)
+			stone2 := g.PyramidValue(row2, col2)
+			if g.IsMatchingPair(stone1, stone2) {
+				moves = append(moves, Move{Source: pos1, Destination: pos2})
+			}
+		}
+		if g.HoldValue() != -1 && g.IsMatchingPair(stone1, g.HoldValue()) {
+			moves = append(moves, Move{Source: pos1, Destination: "HOLD"})
+		}
+		drw1Stone := g.GetCurrentDrawStone()
+		if drw1Stone != -1 && g.IsMatchingPair(stone1, drw1Stone) {
+			moves = append(moves, Move{Source: pos1, Destination: "DRW1"})
+		}
+		if g.HoldValue() == -1 && stone1 != 13 {
+			moves = append(moves, Move{Source: pos1, Destination: "HOLD"})
+		}
+	}
+	if g.HoldValue() != -1 && g.GetCurrentDrawStone() != -1 && g.IsMatchingPair(g.HoldValue(), g.GetCurrentDrawStone()) {
+		moves = append(moves, Move{Source: "HOLD", Destination: "DRW1"})
+	}
+	if g.HoldValue() == -1 && g.GetCurrentDrawStone() != -1 && g.GetCurrentDrawStone() != 13 {
+		moves = append(moves, Move{Source: "DRW1", Destination: "HOLD"})
+	}
+	if g.GetCurrentDrawStone() == 13 {
+		moves = append(moves, Move{Source: "DRW1", Destination: "SMASH"})
+	}
+	if g.allowHoldPlacement && g.hold != -1 {
+		for rowIdx := 0; rowIdx < MaxPyramidRows; rowIdx++ {
+			for colIdx := 0; colIdx < utils.PyramidRowSizes[rowIdx]; colIdx++ {
+				if g.canPlaceHold(rowIdx, colIdx) {
+					pos, _ := utils.IndicesToString(rowIdx, colIdx)
+					moves = append(moves, Move{Source: "HOLD", Destination: pos})
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// DistinctFirstMoves returns the legal moves in the current state canonicalized with
+// CanonicalizeMove and without duplicates, in LegalMoves order, so a pyramid match is listed
+// once whichever stone it is played from.
+func (g *PuzzleGame) DistinctFirstMoves() []Move {
+	moves := []Move{}
+	seen := make(map[Move]bool)
+	for _, move := range g.LegalMoves() {
+		move = CanonicalizeMove(move)
+		if !seen[move] {
+			seen[move] = true
+			moves = append(moves, move)
+		}
+	}
+	return moves
+}
+
+// ForcedMove returns the only clearing move available, if there is exactly one. Draws and
+// parking a stone in an empty HOLD do not count as clearing moves.
+func (g *PuzzleGame) ForcedMove() (Move, bool) {
+	clearing := g.clearingMoves()
+	if len(clearing) != 1 {
+		return Move{}, false
+	}
+	return clearing[0], true
+}
+
+// MobilityScore returns how many clearing moves are available right now: pyramid matches,
+// smashes, and matches against HOLD or DRW1. Draws and HOLD parks are not counted. A low
+// score warns that the board is close to stuck.
+func (g *PuzzleGame) MobilityScore() int {
+	return len(g.clearingMoves())
+}
+
+// clearingMoves returns the legal moves that clear at least one stone.
+func (g *PuzzleGame) clearingMoves() []Move {
+	clearing := []Move{}
+	for _, move := range g.LegalMoves() {
+		parked := move.Destination == "HOLD" && g.hold == -1
+		placed := move.Source == "HOLD" && move.Destination != "DRW1" // Hold placement variant
+		if move.Source == "DRAW" || parked || placed {
+			continue
+		}
+		clearing = append(clearing, move)
+	}
+	return clearing
+}
+
+// IsLegalMove reports whether m is currently legal. Pyramid-to-pyramid matches are accepted in
+// either order.
+func (g *PuzzleGame) IsLegalMove(m Move) bool {
+	mirrored := Move{Source: m.Destination, Destination: m.Source}
+	_, _, srcErr := utils.StringToIndices(m.Source)
+	_, _, dstErr := utils.StringToIndices(m.Destination)
+	pyramidPair := srcErr == nil && dstErr == nil
+
+	for _, legal := range g.LegalMoves() {
+		if legal == m || (pyramidPair && legal == mirrored) {
+			return true
+		}
+	}
+	return false
+}
+
+// Preview reports what m would do without changing g: whether it clears a stone and the score
+// afterwards. It returns an error if m is not currently legal.
+func (g *PuzzleGame) Preview(m Move) (bool, int, error) {
+	if !g.IsLegalMove(m) {
+		return false, 0, fmt.Errorf("move %s -> %s is not legal", m.Source, m.Destination)
+	}
+	preview := g.DeepCopy()
+	cleared := preview.MakeMove(m.Source, m.Destination)
+	return cleared, preview.CalculateScore(), nil
+}
+
+// Describe returns the move as a human-readable solution line, e.g. "Move A3 to HOLD" or
+// "Smash DRW1".
+func (m Move) Describe() string {
+	return m.DescribeLabeled(func(location string) string { return location })
+}
+
+// DescribeLabeled is Describe with each stone location passed through label first, so callers
+// can annotate locations with the stones sitting there.
+func (m Move) DescribeLabeled(label func(location string) string) string {
+	switch {
+	case m.Source == "DRAW" && m.Destination == "DRAW":
+		return "DRAW"
+	case m.Destination == "HOLD":
+		return fmt.Sprintf("Move %s to HOLD", label(m.Source))
+	case m.Source == "HOLD" && m.Destination != "DRW1":
+		// Only the hold placement variant moves HOLD onto the pyramid
+		return fmt.Sprintf("Place %s on %s", label("HOLD"), m.Destination)
+	case m.Source == "HOLD":
+		return fmt.Sprintf("Match %s and %s", label("HOLD"), label(m.Destination))
+	case m.Destination == "SMASH":
+		return fmt.Sprintf("Smash %s", label(m.Source))
+	case m.Source == "DRW1":
+		return fmt.Sprintf("Move %s to %s", label("DRW1"), m.Destination)
+	case m.Destination == "DRW1":
+		return fmt.Sprintf("Match %s to %s", label(m.Source), label("DRW1"))
+	default:
+		return fmt.Sprintf("Match %s and %s", label(m.Source), label(m.Destination))
+	}
+}
+
+// CanonicalizeMove puts a pyramid-to-pyramid match in a fixed order, lexicographically smaller
+// position first, so the same match always reads the same. Other moves are returned unchanged:
+// their order carries meaning (DRW1 -> HOLD parks, HOLD -> DRW1 matches).
+func CanonicalizeMove(m Move) Move {
+	_, _, srcErr := utils.StringToIndices(m.Source)
+	_, _, dstErr := utils.StringToIndices(m.Destination)
+	if srcErr == nil && dstErr == nil && m.Destination < m.Source {
+		return Move{Source: m.Destination, Destination: m.Source}
+	}
+	return m
+}
+
+// EncodeMoves writes moves as a compact single line, e.g. "A1-A5 DRAW DRW1-HOLD". Each move is
+// SOURCE-DESTINATION, except a draw, which is written as just DRAW. Moves are canonicalized
+// first, so equivalent solutions encode identically.
+func EncodeMoves(moves []Move) string {
+	tokens := make([]string, len(moves))
+	for i, move := range moves {
+		move = CanonicalizeMove(move)
+		if move.Source == "DRAW" && move.Destination == "DRAW" {
+			tokens[i] = "DRAW"
+		} else {
+			tokens[i] = move.Source + "-" + move.Destination
+		}
+	}
+	return strings.Join(tokens, " ")
+}
+
+// DecodeMoves parses a string produced by EncodeMoves. Tokens may be separated by any
+// whitespace.
+func DecodeMoves(encoded string) ([]Move, error) {
+	tokens := strings.Fields(encoded)
+	moves := make([]Move, 0, len(tokens))
+	for i, token := range tokens {
+		if token == "DRAW" {
+			moves = append(moves, Move{Source: "DRAW", Destination: "DRAW"})
+			continue
+		}
+		source, destination, ok := strings.Cut(token, "-")
+		if !ok || source == "" || destination == "" {
+			return nil, fmt.Errorf("invalid move %q at index %d", token, i)
+		}
+		moves = append(moves, Move{Source: source, Destination: destination})
+	}
+	return moves, nil
+}
+
+// ErrMalformedMove reports a move whose source or destination is not a location a move can have.
+var ErrMalformedMove = errors.New("malformed move")
+
+// ValidateMoveFormat checks that m is well formed without needing a board: both ends are
+// DRAW, HOLD, DRW1, SMASH or a pyramid position such as "C2", DRAW only appears as DRAW-DRAW,
+// and SMASH only as a destination. It does not check that m is legal in any game; see
+// IsLegalMove for that. Errors wrap ErrMalformedMove.
+func ValidateMoveFormat(m Move) error {
+	if m.Source == "DRAW" || m.Destination == "DRAW" {
+		if m.Source != m.Destination {
+			return fmt.Errorf("%w: DRAW must be both source and destination, got %s -> %s", ErrMalformedMove, m.Source, m.Destination)
+		}
+		return nil
+	}
+	if m.Source == "SMASH" {
+		return fmt.Errorf("%w: SMASH cannot be a source", ErrMalformedMove)
+	}
+	for _, location := range []string{m.Source, m.Destination} {
+		switch location {
+		case "HOLD", "DRW1", "SMASH":
+			continue
+		}
+		if _, _, err := utils.StringToIndices(location); err != nil {
+			return fmt.Errorf("%w: %v", ErrMalformedMove, err)
+		}
+	}
+	return nil
+}
+
+// Location codes used by EncodeMovesBinary. Pyramid positions take codes 0-27 in row order,
+// A1 first and G1 last.
+const (
+	holdCode    byte = TotalPyramidStones
+	drawTopCode byte = TotalPyramidStones + 1
+	smashCode   byte = TotalPyramidStones + 2
+	drawCode    byte = TotalPyramidStones + 3
+	invalidCode byte = 0xFF
+)
+
+// EncodeMovesBinary packs moves two bytes each, one location code for the source and one for
+// the destination, for storing large numbers of solutions. Moves are canonicalized first, as
+// in EncodeMoves. A location that is not part of the game encodes as 0xFF, which
+// DecodeMovesBinary rejects.
+func EncodeMovesBinary(moves []Move) []byte {
+	encoded := make([]byte, 0, 2*len(moves))
+	for _, move := range moves {
+		move = CanonicalizeMove(move)
+		encoded = append(encoded, locationCode(move.Source), locationCode(move.Destination))
+	}
+	return encoded
+}
+
+// DecodeMovesBinary unpacks moves written by EncodeMovesBinary.
+func DecodeMovesBinary(encoded []byte) ([]Move, error) {
+	if len(encoded)%2 != 0 {
+		return nil, fmt.Errorf("binary moves must have an even length, got %d bytes", len(encoded))
+	}
+	moves := make([]Move, 0, len(encoded)/2)
+	for i := 0; i < len(encoded); i += 2 {
+		source, srcOK := codeLocation(encoded[i])
+		destination, dstOK := codeLocation(encoded[i+1])
+		if !srcOK || !dstOK {
+			return nil, fmt.Errorf("invalid move code %#02x %#02x at index %d", encoded[i], encoded[i+1], i/2)
+		}
+		moves = append(moves, Move{Source: source, Destination: destination})
+	}
+	return moves, nil
+}
+
+// locationCode returns the binary code for a move location.
+func locationCode(location string) byte {
+	switch location {
+	case "HOLD":
+		return holdCode
+	case "DRW1":
+		return drawTopCode
+	case "SMASH":
+		return smashCode
+	case "DRAW":
+		return drawCode
+	}
+	row, col, err := utils.StringToIndices(location)
+	if err != nil {
+		return invalidCode
+	}
+	code := col
+	for r := 0; r < row; r++ {
+		code += utils.PyramidRowSizes[r]
+	}
+	return byte(code)
+}
+
+// codeLocation is the inverse of locationCode.
+func codeLocation(code byte) (string, bool) {
+	switch code {
+	case holdCode:
+		return "HOLD", true
+	case drawTopCode:
+		return "DRW1", true
+	case smashCode:
+		return "SMASH", true
+	case drawCode:
+		return "DRAW", true
+	}
+	index := int(code)
+	for row, rowSize := range utils.PyramidRowSizes {
+		if index < rowSize {
+			location, err := utils.IndicesToString(row, index)
+			return location, err == nil
+		}
+		index -= rowSize
+	}
+	return "", false
+}
@@ -0,0 +1,193 @@
+package game
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestForcedMove(t *testing.T) {
+	single := newTestGame(t, pyramidWith(t, 7, map[string]int{"A1": 1, "A2": 2}), []int{7})
+	move, ok := single.ForcedMove()
+	if want := (Move{Source: "A1", Destination: "A2"}); !ok || CanonicalizeMove(move) != want {
+		t.Errorf("ForcedMove() = %v, %v, want %v, true", move, ok, want)
+	}
+
+	several := newTestGame(t, pyramidWith(t, 7, map[string]int{"A1": 1, "A2": 2, "A3": 3, "A4": 4}), []int{7})
+	if move, ok := several.ForcedMove(); ok {
+		t.Errorf("ForcedMove() with two matches = %v, true, want false", move)
+	}
+}
+
+func TestPreviewMatchesMakeMove(t *testing.T) {
+	g := newTestGame(t, examplePyramid, exampleDrawPile)
+	g.MakeMove("DRAW", "DRAW")
+	before := g.Hash()
+
+	for _, move := range g.LegalMoves() {
+		cleared, score, err := g.Preview(move)
+		if err != nil {
+			t.Fatalf("Preview(%v): %v", move, err)
+		}
+		if g.Hash() != before {
+			t.Fatalf("Preview(%v) changed the game", move)
+		}
+		played := g.DeepCopy()
+		if wantCleared := played.MakeMove(move.Source, move.Destination); cleared != wantCleared || score != played.CalculateScore() {
+			t.Errorf("Preview(%v) = %v, %d, want %v, %d as MakeMove gives", move, cleared, score, wantCleared, played.CalculateScore())
+		}
+	}
+
+	if _, _, err := g.Preview(Move{Source: "G1", Destination: "SMASH"}); err == nil {
+		t.Error("Preview of a buried stone is not an error")
+	}
+}
+
+func TestCanonicalizeMove(t *testing.T) {
+	tests := []struct {
+		move, want Move
+	}{
+		{Move{"A5", "A1"}, Move{"A1", "A5"}},
+		{Move{"A1", "A5"}, Move{"A1", "A5"}},
+		{Move{"B2", "A7"}, Move{"A7", "B2"}},
+		{Move{"DRW1", "HOLD"}, Move{"DRW1", "HOLD"}},
+		{Move{"HOLD", "DRW1"}, Move{"HOLD", "DRW1"}},
+		{Move{"A3", "HOLD"}, Move{"A3", "HOLD"}},
+		{Move{"A3", "SMASH"}, Move{"A3", "SMASH"}},
+	}
+	for _, tt := range tests {
+		if got := CanonicalizeMove(tt.move); got != tt.want {
+			t.Errorf("CanonicalizeMove(%v) = %v, want %v", tt.move, got, tt.want)
+		}
+	}
+	if EncodeMoves([]Move{{"A5", "A1"}}) != EncodeMoves([]Move{{"A1", "A5"}}) {
+		t.Error("the two orderings of a pyramid match encode differently")
+	}
+}
+
+func TestMobilityScore(t *testing.T) {
+	open := newTestGame(t, pyramidWith(t, 7, map[string]int{"A1": 1, "A2": 2, "A3": 3, "A4": 4, "A5": 13}), []int{2})
+	// A1-A2, A3-A4, A5 smashed and A1 with the 2 as DRW1
+	if got := open.MobilityScore(); got != 4 {
+		t.Errorf("MobilityScore() on an open board = %d, want 4", got)
+	}
+	locked := newTestGame(t, filled(TotalPyramidStones, 7), []int{7})
+	if got := locked.MobilityScore(); got != 0 {
+		t.Errorf("MobilityScore() on a locked board = %d, want 0", got)
+	}
+}
+
+func TestMoveDescribe(t *testing.T) {
+	tests := []struct {
+		move Move
+		want string
+	}{
+		{Move{"DRAW", "DRAW"}, "DRAW"},
+		{Move{"A3", "HOLD"}, "Move A3 to HOLD"},
+		{Move{"DRW1", "HOLD"}, "Move DRW1 to HOLD"},
+		{Move{"HOLD", "B2"}, "Place HOLD on B2"},
+		{Move{"HOLD", "DRW1"}, "Match HOLD and DRW1"},
+		{Move{"A3", "SMASH"}, "Smash A3"},
+		{Move{"DRW1", "SMASH"}, "Smash DRW1"},
+		{Move{"DRW1", "A4"}, "Move DRW1 to A4"},
+		{Move{"A4", "DRW1"}, "Match A4 to DRW1"},
+		{Move{"A1", "A5"}, "Match A1 and A5"},
+	}
+	for _, tt := range tests {
+		if got := tt.move.Describe(); got != tt.want {
+			t.Errorf("%v.Describe() = %q, want %q", tt.move, got, tt.want)
+		}
+	}
+	labeled := Move{"A1", "A5"}.DescribeLabeled(func(location string) string { return "<" + location + ">" })
+	if want := "Match <A1> and <A5>"; labeled != want {
+		t.Errorf("DescribeLabeled = %q, want %q", labeled, want)
+	}
+}
+
+func TestBinaryMovesRoundTrip(t *testing.T) {
+	moves := []Move{
+		{"DRAW", "DRAW"}, {"A1", "A5"}, {"DRW1", "HOLD"}, {"HOLD", "DRW1"},
+		{"A3", "SMASH"}, {"DRW1", "SMASH"}, {"G1", "DRW1"}, {"HOLD", "B2"},
+	}
+	encoded := EncodeMovesBinary(moves)
+	if len(encoded) != 2*len(moves) {
+		t.Errorf("encoded %d moves in %d bytes, want %d", len(moves), len(encoded), 2*len(moves))
+	}
+	if text := EncodeMoves(moves); len(encoded) >= len(text)/2 {
+		t.Errorf("binary encoding is %d bytes, not much smaller than the %d-byte text", len(encoded), len(text))
+	}
+	decoded, err := DecodeMovesBinary(encoded)
+	if err != nil {
+		t.Fatalf("DecodeMovesBinary: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, moves) {
+		t.Errorf("round trip = %v, want %v", decoded, moves)
+	}
+
+	for _, bad := range [][]byte{encoded[:3], {0xFF, 0xFF}} {
+		if _, err := DecodeMovesBinary(bad); err == nil {
+			t.Errorf("DecodeMovesBinary(%v) did not fail", bad)
+		}
+	}
+}
+
+func TestHoldPlacementMoves(t *testing.T) {
+	placements := func(allowed bool) []Move {
+		g := newTestGame(t, filled(TotalPyramidStones, 13), []int{7})
+		g.SetAllowHoldPlacement(allowed)
+		g.MakeMove("DRW1", "HOLD")
+		for _, pos := range []string{"A1", "A2", "B1"} {
+			g.MakeMove(pos, "SMASH")
+		}
+		found := []Move{}
+		for _, move := range g.LegalMoves() {
+			if move.Source == "HOLD" && move.Destination != "DRW1" {
+				found = append(found, move)
+			}
+		}
+		return found
+	}
+
+	if got := placements(false); len(got) != 0 {
+		t.Errorf("placements without the variant = %v, want none", got)
+	}
+	// A1 supports nothing now that B1 is gone; A2 and B1 would still block B2 and C1
+	if got, want := placements(true), []Move{{"HOLD", "A1"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("placements with the variant = %v, want %v", got, want)
+	}
+}
+
+func TestValidateMoveFormat(t *testing.T) {
+	for _, m := range []Move{{"DRAW", "DRAW"}, {"A1", "A5"}, {"C2", "HOLD"}, {"DRW1", "SMASH"}, {"HOLD", "DRW1"}} {
+		if err := ValidateMoveFormat(m); err != nil {
+			t.Errorf("ValidateMoveFormat(%v) = %v, want nil", m, err)
+		}
+	}
+	for _, m := range []Move{{"Z9", "A1"}, {"A1", "HELD"}, {"A8", "A1"}, {"G2", "SMASH"}, {"SMASH", "A1"}, {"DRAW", "A1"}} {
+		if err := ValidateMoveFormat(m); !errors.Is(err, ErrMalformedMove) {
+			t.Errorf("ValidateMoveFormat(%v) = %v, want an error wrapping ErrMalformedMove", m, err)
+		}
+	}
+}
+
+func TestDistinctFirstMoves(t *testing.T) {
+	// 7s never match each other, so with a 7 parked in HOLD and another as DRW1 the only
+	// moves are DRAW, smashing the 13 and the one match of the 1 with the 2
+	g := newTestGame(t, pyramidWith(t, 7, map[string]int{"A5": 2, "A6": 13, "A7": 1}), []int{7, 7})
+	g.MakeMove("DRW1", "HOLD")
+
+	want := []Move{{"DRAW", "DRAW"}, {"A6", "SMASH"}, {"A5", "A7"}}
+	if got := g.DistinctFirstMoves(); !reflect.DeepEqual(got, want) {
+		t.Errorf("DistinctFirstMoves() = %v, want %v", got, want)
+	}
+}
+
+func TestLegalMovesWithoutDrawPile(t *testing.T) {
+	g := newTestGame(t, pyramidWith(t, 7, map[string]int{"A1": 1, "A2": 2}), nil)
+	if g.IsLegalMove(Move{"DRAW", "DRAW"}) {
+		t.Error("DRAW is legal with no draw pile")
+	}
+	if !g.IsLegalMove(Move{"A1", "A2"}) {
+		t.Error("the 1-2 match is not legal with no draw pile")
+	}
+}
@@ -2,27 +2,59 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"time"
 
 	"pyramid_solver_go_local/game"   // <--- Ensure this path is correct
 	"pyramid_solver_go_local/solver" // <--- Ensure this path is correct
+	"pyramid_solver_go_local/utils"
 	
 )
 
+// jsonlResult is one line of -jsonl output.
+type jsonlResult struct {
+	Index  int         `json:"index"`
+	Score  int         `json:"score"`
+	Solved bool        `json:"solved"`
+	Moves  []game.Move `json:"moves"`
+}
+
 func main() {
-	fmt.Println("Welcome to the Pyramid Stone Puzzle Solver!")
+	jsonlOutput := flag.Bool("jsonl", false, "also print each solved puzzle as a JSON line")
+	letterNotation := flag.Bool("letters", false, "show each stone's input letter and value in the solution")
+	compactNotation := flag.Bool("compact", false, "also print the puzzle letters and the solution on one line, in the format the verify subcommand reads")
+	resumeFile := flag.String("resume", "", "build on the solution saved in this file, if any, and save the best solution back to it")
+	verifySolve := flag.Bool("verify", false, "replay the best solution after solving and warn if it is illegal or scores differently")
+	verbosity := flag.Int("v", int(solver.Normal), "output level: 0 prints only prompts and the solution, 1 is normal, 2 adds solver debug output")
+	flag.Parse()
+
+	if flag.Arg(0) == "verify" {
+		os.Exit(runVerify(flag.Args()[1:], os.Stdout))
+	}
+
+	verbose := *verbosity >= int(solver.Normal)
+	if verbose {
+		fmt.Println("Welcome to the Pyramid Stone Puzzle Solver!")
+	}
 	reader := bufio.NewReader(os.Stdin)
 
-	for { // Main loop to solve multiple puzzles
+	for puzzleIndex := 0; ; puzzleIndex++ { // Main loop to solve multiple puzzles
 		gameInstance := game.NewPuzzleGame()
 
 		fmt.Println("\nHow would you like to set up the puzzle?")
 		fmt.Println("1. Enter pyramid and draw pile values manually")
 		fmt.Println("2. Use the example puzzle from our discussion")
-		fmt.Print("Enter your choice (1 or 2): ")
+		fmt.Println("3. Enter the pyramid, then the draw pile one segment at a time")
+		fmt.Print("Enter your choice (1, 2 or 3): ")
 
 		choiceStr, _ := reader.ReadString('\n')
 		choiceStr = strings.TrimSpace(choiceStr)
@@ -32,7 +64,7 @@ func main() {
 		var drawPileStones []int
 		var err error
 
-		if choice == 1 {
+		if choice == 1 || choice == 3 {
 			pyramidStones, err = getPyramidInput(reader)
 			if err != nil {
 				fmt.Printf("Error getting pyramid input: %v\n", err)
@@ -44,7 +76,11 @@ func main() {
 				}
 				break // Exit if not retrying
 			}
-			drawPileStones, err = getDrawPileInput(reader) // Updated call
+			if choice == 3 {
+				drawPileStones, err = getSegmentedDrawPileInput(reader)
+			} else {
+				drawPileStones, err = getDrawPileInput(reader) // Updated call
+			}
 			if err != nil {
 				fmt.Printf("Error getting draw pile input: %v\n", err)
 				// Optionally, ask if they want to try again or exit
@@ -73,19 +109,76 @@ func main() {
 			break // Exit if not retrying
 		}
 
-		fmt.Println("\nInitial Game State:")
-		gameInstance.PrintState()
+		if verbose {
+			fmt.Println("\nInitial Game State:")
+			gameInstance.PrintState()
+		}
 
 		puzzleSolver := solver.NewPuzzleSolver(gameInstance)
+		puzzleSolver.Verbosity = solver.Verbosity(*verbosity)
+		if verbose {
+			puzzleSolver.Progress = printSolveProgress
+		}
+		if *resumeFile != "" {
+			seedMoves, err := loadSolutionFile(*resumeFile)
+			if err != nil {
+				fmt.Printf("Error loading %s: %v\n", *resumeFile, err)
+			} else if len(seedMoves) > 0 {
+				if verbose {
+					fmt.Printf("Resuming from the %d-move solution in %s.\n", len(seedMoves), *resumeFile)
+				}
+				puzzleSolver.SeedMoves = seedMoves
+			}
+		}
 
-        fmt.Println("\nSolving puzzle...")
-        bestMoves, bestScore := puzzleSolver.SolveMonteCarlo(100000) // Set iterations to 100,000
-
+        const iterations = 100000
+        if verbose {
+            fmt.Println("\nSolving puzzle... (Ctrl-C stops early and keeps the best solution so far)")
+            fmt.Printf("This should take about %s.\n", puzzleSolver.EstimateSolveTime(iterations).Round(time.Second))
+        }
+        ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+        bestMoves, bestScore := puzzleSolver.SolveMonteCarloContext(ctx, iterations)
+        interrupted := ctx.Err() != nil
+        stop()
+        if interrupted {
+            fmt.Println("\nSolve interrupted.")
+        }
 
-		fmt.Printf("\nBest solution found - Score: %d, Moves: %d\n", bestScore, len(bestMoves))
+		solved := puzzleSolver.LastStats().Solved
+		start := puzzleSolver.LastStart()
+		reportSolve(os.Stdout, gameInstance, start, bestMoves, bestScore, solved, *verifySolve, verbose)
 
-		solutionText := formatSolution(bestMoves, bestScore)
+		var letterSource *game.PuzzleGame
+		if *letterNotation {
+			letterSource = start
+		}
+		solutionText := formatSolution(bestMoves, bestScore, letterSource)
 		fmt.Println("\n" + solutionText)
+		if *compactNotation {
+			// The puzzle lines are a puzzle file for the verify subcommand
+			if gameInstance.UnknownDrawStones() > 0 {
+				fmt.Println("The unknown draw pile stones are shown as the solver sampled them.")
+			}
+			fmt.Println("Puzzle:\n" + sampledPuzzleString(gameInstance, start))
+			fmt.Println("Compact: " + game.EncodeMoves(bestMoves))
+		}
+
+		if *resumeFile != "" {
+			if err := os.WriteFile(*resumeFile, []byte(game.EncodeMoves(bestMoves)+"\n"), 0o644); err != nil {
+				fmt.Printf("Error saving solution: %v\n", err)
+			}
+		}
+
+		if *jsonlOutput {
+			record := jsonlResult{Index: puzzleIndex, Score: bestScore, Solved: solved, Moves: bestMoves}
+			if err := writeJSONLResult(os.Stdout, record); err != nil {
+				fmt.Printf("Error writing JSON line: %v\n", err)
+			}
+		}
+
+		if interrupted {
+			return // Ctrl-C means the user is done; exit after showing the partial result
+		}
 
 		// Ask to solve another puzzle
 		fmt.Print("\nSolve another pyramid? (y/n): ")
@@ -115,14 +208,10 @@ func getPyramidInput(reader *bufio.Reader) ([]int, error) {
             continue
         }
 
-        pyramidStones := make([]int, game.TotalPyramidStones)
-        for i, char := range inputStr {
-            stone, err := charToInt(char)
-            if err != nil {
-                fmt.Println(err)
-                continue // Go to the next input attempt
-            }
-            pyramidStones[i] = stone
+        pyramidStones, err := parseStoneLetters(inputStr)
+        if err != nil {
+            fmt.Println(err)
+            continue // Go to the next input attempt
         }
         return pyramidStones, nil
     }
@@ -133,6 +222,7 @@ func getDrawPileInput(reader *bufio.Reader) ([]int, error) {
     fmt.Println("\n=== DRAW PILE INPUT ===")
     fmt.Println("Enter 24 characters (a-u) for the draw pile stones.")
     fmt.Println("a=1, s=2, d=3, f=4, g=5, h=6, j=7, k=8, l=9, r=10, t=11, y=12, u=13")
+    fmt.Println("Use ? for a stone you cannot see; the solver tries plausible values for it.")
     fmt.Println("No spaces between characters.")
 
     for {
@@ -145,69 +235,283 @@ func getDrawPileInput(reader *bufio.Reader) ([]int, error) {
             continue
         }
 
-        drawPileStones := make([]int, game.MaxDrawPileSegments*game.StonesPerSegment)
-        for i, char := range inputStr {
-            stone, err := charToInt(char)
-            if err != nil {
-                fmt.Println(err)
-                continue // Go to the next input attempt
-            }
-            drawPileStones[i] = stone
+        drawPileStones, err := parseStoneLetters(inputStr)
+        if err != nil {
+            fmt.Println(err)
+            continue // Go to the next input attempt
         }
         return drawPileStones, nil
     }
 }
 
 
-// charToInt converts a character to its corresponding integer value.
-func charToInt(char rune) (int, error) {
-    switch char {
-    case 'a': return 1, nil
-    case 's': return 2, nil
-    case 'd': return 3, nil
-    case 'f': return 4, nil
-    case 'g': return 5, nil
-    case 'h': return 6, nil
-    case 'j': return 7, nil
-    case 'k': return 8, nil
-    case 'l': return 9, nil
-    case 'r': return 10, nil
-    case 't': return 11, nil
-    case 'y': return 12, nil
-    case 'u': return 13, nil
-    default: return -1, fmt.Errorf("invalid character: %c", char)
-    }
+// getSegmentedDrawPileInput reads the draw pile one segment per line, the way the game shows
+// it. Every segment must be full except the last; a blank line ends the pile early.
+func getSegmentedDrawPileInput(reader *bufio.Reader) ([]int, error) {
+	fmt.Println("\n=== DRAW PILE INPUT (BY SEGMENT) ===")
+	fmt.Printf("Enter %d characters (a-u) per segment, from segment 1 to %d.\n", game.StonesPerSegment, game.MaxDrawPileSegments)
+	fmt.Println("a=1, s=2, d=3, f=4, g=5, h=6, j=7, k=8, l=9, r=10, t=11, y=12, u=13")
+	fmt.Println("Use ? for a stone you cannot see; the solver tries plausible values for it.")
+	fmt.Println("A shorter segment ends the pile; press Enter on an empty line to stop early.")
+
+	drawPileStones := []int{}
+	for segmentIdx := 0; segmentIdx < game.MaxDrawPileSegments; {
+		fmt.Printf("\nSegment %d: ", segmentIdx+1)
+		inputStr, err := reader.ReadString('\n')
+		inputStr = strings.TrimSpace(inputStr)
+		if inputStr == "" {
+			if err != nil && segmentIdx == 0 {
+				return nil, fmt.Errorf("no draw pile entered: %w", err)
+			}
+			break
+		}
+
+		segment, parseErr := parseStoneLetters(inputStr)
+		if parseErr == nil && len(segment) > game.StonesPerSegment {
+			parseErr = fmt.Errorf("too many characters, please enter at most %d", game.StonesPerSegment)
+		}
+		if parseErr != nil {
+			if err != nil {
+				return nil, parseErr // No more input to retry with
+			}
+			fmt.Println(parseErr)
+			continue
+		}
+		drawPileStones = append(drawPileStones, segment...)
+		segmentIdx++
+		if len(segment) < game.StonesPerSegment || err != nil {
+			break // A partial segment can only be the last one
+		}
+	}
+	return drawPileStones, nil
+}
+
+
+// parseStoneLetters converts a string of stone letters (see utils.LetterToStone) to stone values.
+func parseStoneLetters(letters string) ([]int, error) {
+	stones := make([]int, 0, len(letters))
+	for _, char := range letters {
+		stone, err := utils.LetterToStone(char)
+		if err != nil {
+			return nil, err
+		}
+		stones = append(stones, stone)
+	}
+	return stones, nil
+}
+
+
+// loadPuzzleFile reads a puzzle file: the pyramid letters on the first non-blank line and the
+// draw pile letters on the second, in the same notation as the interactive input.
+func loadPuzzleFile(path string) (*game.PuzzleGame, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lines := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 || len(lines) > 2 {
+		return nil, fmt.Errorf("puzzle file must have a pyramid line and a draw pile line, got %d lines", len(lines))
+	}
+
+	pyramidStones, err := parseStoneLetters(lines[0])
+	if err != nil {
+		return nil, fmt.Errorf("pyramid: %w", err)
+	}
+	drawPileStones := []int{}
+	if len(lines) == 2 {
+		if drawPileStones, err = parseStoneLetters(lines[1]); err != nil {
+			return nil, fmt.Errorf("draw pile: %w", err)
+		}
+	}
+
+	puzzle := game.NewPuzzleGame()
+	if err := puzzle.SetupCustomGame(pyramidStones, drawPileStones); err != nil {
+		return nil, err
+	}
+	return puzzle, nil
+}
+
+
+// runVerify implements "verify <puzzle-file> <moves...>": it replays an encoded solution on
+// the puzzle and reports the score, or the first illegal move. It returns the exit status.
+func runVerify(args []string, out io.Writer) int {
+	if len(args) < 2 {
+		fmt.Fprintln(out, "usage: pyramid_solver_go verify <puzzle-file> <moves>")
+		return 2
+	}
+	puzzle, err := loadPuzzleFile(args[0])
+	if err != nil {
+		fmt.Fprintf(out, "Error loading puzzle: %v\n", err)
+		return 1
+	}
+	moves, err := game.DecodeMoves(strings.Join(args[1:], " "))
+	if err != nil {
+		fmt.Fprintf(out, "Error decoding moves: %v\n", err)
+		return 1
+	}
+
+	final, err := solver.Replay(puzzle, moves)
+	if err != nil {
+		var illegal *solver.IllegalMoveError
+		if errors.As(err, &illegal) {
+			fmt.Fprintf(out, "Illegal solution: move %d (%s) is not legal at that point.\n",
+				illegal.Index, game.EncodeMoves([]game.Move{illegal.Move}))
+			return 1
+		}
+		fmt.Fprintf(out, "Error replaying solution: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(out, "Legal solution of %d moves. Score: %d, Solved: %t\n", len(moves), final.CalculateScore(), final.IsSolved())
+	return 0
+}
+
+
+// verifySolution replays moves on a copy of g and checks they are legal and reach score.
+func verifySolution(g *game.PuzzleGame, moves []game.Move, score int) error {
+	final, err := solver.Replay(g, moves)
+	if err != nil {
+		return err
+	}
+	if replayed := final.CalculateScore(); replayed != score {
+		return fmt.Errorf("replayed score is %d, but the solver reported %d", replayed, score)
+	}
+	return nil
+}
+
+
+// reportSolve prints the summary of a solve of g: the score against the maximum, whether the
+// pyramid was cleared and, with verify set, whether moves replay legally to score. solved is
+// whether the solver's best rollout cleared the pyramid. start is the game moves were played
+// from, the solver's LastStart: g itself, unless g's draw pile has unknown stones and the
+// solver sampled them, in which case moves are verified on that sampled pile and the output
+// says so. Only the verification result is printed unless verbose is set.
+func reportSolve(out io.Writer, g, start *game.PuzzleGame, moves []game.Move, score int, solved, verify, verbose bool) {
+	sampled := ""
+	if unknown := g.UnknownDrawStones(); unknown > 0 {
+		sampled = fmt.Sprintf(" of the draw pile with its %d unknown stones as sampled", unknown)
+	}
+	if verbose {
+		fmt.Fprintf(out, "\nBest solution found - Score: %d, Moves: %d\n", score, len(moves))
+	}
+	if verify {
+		if err := verifySolution(start, moves, score); err != nil {
+			fmt.Fprintf(out, "WARNING: the solution failed verification, which points to a solver or engine bug: %v\n", err)
+		} else if verbose {
+			fmt.Fprintf(out, "Solution verified: it replays legally to the reported score%s.\n", sampled)
+		}
+	}
+	if !verbose {
+		return
+	}
+
+	maxScore := g.MaxPossibleScore()
+	fmt.Fprintf(out, "Score %d / max ~%d (%d%%) - %s\n", score, maxScore, score*100/maxScore,
+		game.ClassifyScore(score, maxScore, solved))
+	if !solved {
+		final := start.DeepCopy()
+		for _, move := range moves {
+			final.MakeMove(move.Source, move.Destination)
+		}
+		fmt.Fprintf(out, "Partial clear%s - %d%% - score %d (not solved).\n",
+			sampled, int(final.CompletionPercentage()*100), final.ScoreBreakdown().Total)
+	}
 }
 
-// formatSolution formats the solution into a readable string.
-func formatSolution(moves []game.Move, score int) string {
+
+// sampledPuzzleString returns g's starting letters, as InitialPuzzleString does, but with the
+// draw pile line taken from start, so that stones unknown in g read as the values the solver
+// sampled for them. start must be unplayed, like a solver's LastStart for a freshly set up g.
+func sampledPuzzleString(g, start *game.PuzzleGame) string {
+	if g.UnknownDrawStones() == 0 {
+		return g.InitialPuzzleString()
+	}
+	pyramidLetters, _, _ := strings.Cut(g.InitialPuzzleString(), "\n")
+	drawStones := []int{}
+	for _, segment := range start.DrawPile() {
+		drawStones = append(drawStones, segment...)
+	}
+	return pyramidLetters + "\n" + utils.StonesToLetters(drawStones)
+}
+
+
+// printSolveProgress reports solve progress on a single updating line, with an estimate of
+// the time left.
+func printSolveProgress(simsRun, simsTotal int, elapsed time.Duration) {
+	if simsTotal <= 0 {
+		return
+	}
+	fraction := float64(simsRun) / float64(simsTotal)
+	eta := "estimating time left"
+	if remaining := solver.EstimateRemaining(fraction, elapsed); remaining >= 0 {
+		eta = fmt.Sprintf("about %s left", remaining.Round(time.Second))
+	}
+	fmt.Printf("\rSimulated %d/%d (%d%%), %s   ", simsRun, simsTotal, simsRun*100/simsTotal, eta)
+}
+
+
+// loadSolutionFile reads moves saved by -resume. A missing file is not an error; it just
+// holds no solution yet.
+func loadSolutionFile(path string) ([]game.Move, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return game.DecodeMoves(string(data))
+}
+
+
+// writeJSONLResult writes record to w as a single JSON line. Each call uses its own Encoder
+// and a single Write, so lines from successive puzzles never interleave.
+func writeJSONLResult(w io.Writer, record jsonlResult) error {
+	return json.NewEncoder(w).Encode(record)
+}
+
+
+// formatSolution formats the solution into a readable string. When start is non-nil the moves
+// are replayed from it and every stone is shown with its input letter and value, e.g.
+// "Match A3(h=6) and B2(g=5)".
+func formatSolution(moves []game.Move, score int, start *game.PuzzleGame) string {
     var sb strings.Builder
     sb.WriteString(fmt.Sprintf("Final Score: %d\n", score))
     sb.WriteString("\nStep-by-Step Solution:\n")
 
+    var replay *game.PuzzleGame
+    if start != nil {
+        replay = start.DeepCopy()
+    }
+    label := func(location string) string {
+        if replay == nil {
+            return location
+        }
+        return stoneLabel(replay, location)
+    }
+
     for _, move := range moves { // Removed the index 'i' from the loop
-        // sb.WriteString(fmt.Sprintf("%d. ", i+1))  // Remove this line - no longer printing the number
-
-        switch {
-        case move.Source == "DRAW" && move.Destination == "DRAW":
-            sb.WriteString("DRAW\n")
-        case move.Destination == "HOLD":
-            sb.WriteString(fmt.Sprintf("Move %s to HOLD\n", move.Source))
-        case move.Source == "HOLD":
-            sb.WriteString(fmt.Sprintf("Match HOLD and %s\n", move.Destination))
-        case move.Destination == "SMASH":
-            sb.WriteString(fmt.Sprintf("Smash %s\n", move.Source))
-        case move.Source == "DRW1":
-            if move.Destination == "SMASH" {
-                sb.WriteString("Smash DRW1\n")
-            } else {
-                sb.WriteString(fmt.Sprintf("Move DRW1 to %s\n", move.Destination))
-            }
-        case move.Destination == "DRW1":
-            sb.WriteString(fmt.Sprintf("Match %s to DRW1\n", move.Source))
-        default:
-            sb.WriteString(fmt.Sprintf("Match %s and %s\n", move.Source, move.Destination))
+        sb.WriteString(move.DescribeLabeled(label) + "\n")
+
+        if replay != nil {
+            replay.MakeMove(move.Source, move.Destination)
         }
     }
     return sb.String()
 }
+
+
+// stoneLabel returns location annotated with the letter and value of the stone currently
+// there, e.g. "A3(h=6)". Locations without a stone are returned as-is.
+func stoneLabel(g *game.PuzzleGame, location string) string {
+    stone := g.LocationValue(location)
+    letter, err := utils.StoneToLetter(stone)
+    if err != nil {
+        return location
+    }
+    return fmt.Sprintf("%s(%c=%d)", location, letter, stone)
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"pyramid_solver_go_local/game"
+	"pyramid_solver_go_local/solver"
+)
+
+// exampleGame sets up the example puzzle, with the draw pile stones given as letters.
+func exampleGame(t *testing.T, drawPileLetters string) *game.PuzzleGame {
+	t.Helper()
+	pyramid := []int{12, 10, 11, 6, 11, 7, 12, 11, 5, 1, 4, 1, 4, 5, 10, 8, 11, 9, 7, 2, 9, 6, 2, 13, 9, 10, 12, 13}
+	drawPile, err := parseStoneLetters(drawPileLetters)
+	if err != nil {
+		t.Fatalf("parseStoneLetters: %v", err)
+	}
+	g := game.NewPuzzleGame()
+	if err := g.SetupCustomGame(pyramid, drawPile); err != nil {
+		t.Fatalf("SetupCustomGame: %v", err)
+	}
+	return g
+}
+
+// solveAndReport solves g briefly and returns what reportSolve prints with -verify, and the
+// game the solution was played from.
+func solveAndReport(t *testing.T, g *game.PuzzleGame) (string, *game.PuzzleGame) {
+	t.Helper()
+	s := solver.NewPuzzleSolver(g)
+	s.Verbosity = solver.Quiet
+	moves, score := s.SolveMonteCarlo(500)
+	var out bytes.Buffer
+	reportSolve(&out, g, s.LastStart(), moves, score, s.LastStats().Solved, true, true)
+	return out.String(), s.LastStart()
+}
+
+func TestReportSolveVerifiesOnSampledPile(t *testing.T) {
+	g := exampleGame(t, "hdk??rsy")
+	out, start := solveAndReport(t, g)
+	if strings.Contains(out, "WARNING") {
+		t.Errorf("solution on a sampled draw pile failed verification:\n%s", out)
+	}
+	if !strings.Contains(out, "Solution verified: it replays legally to the reported score of the draw pile with its 2 unknown stones as sampled") {
+		t.Errorf("output does not say the solution was verified on the sampled pile:\n%s", out)
+	}
+
+	path := filepath.Join(t.TempDir(), "puzzle.txt")
+	if err := os.WriteFile(path, []byte(sampledPuzzleString(g, start)+"\n"), 0o644); err != nil {
+		t.Fatalf("writing puzzle file: %v", err)
+	}
+	loaded, err := loadPuzzleFile(path)
+	if err != nil {
+		t.Fatalf("loading the compact puzzle: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.DrawPile(), start.DrawPile()) {
+		t.Errorf("compact puzzle draw pile = %v, want the sampled %v", loaded.DrawPile(), start.DrawPile())
+	}
+	if start.UnknownDrawStones() != 0 {
+		t.Errorf("LastStart has %d unknown stones, want them sampled", start.UnknownDrawStones())
+	}
+}
+
+func TestReportSolveVerifiesKnownPile(t *testing.T) {
+	out, _ := solveAndReport(t, exampleGame(t, "hdklrsy"))
+	if !strings.Contains(out, "Solution verified") {
+		t.Errorf("solution on a known draw pile was not verified:\n%s", out)
+	}
+}
+
+func TestWriteJSONLResultLines(t *testing.T) {
+	records := []jsonlResult{
+		{Index: 0, Score: 4200, Solved: true, Moves: []game.Move{{Source: "A1", Destination: "A5"}}},
+		{Index: 1, Score: 900, Solved: false, Moves: []game.Move{{Source: "DRAW", Destination: "DRAW"}}},
+	}
+	var out bytes.Buffer
+	for _, record := range records {
+		if err := writeJSONLResult(&out, record); err != nil {
+			t.Fatalf("writeJSONLResult: %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != len(records) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(records), out.String())
+	}
+	for i, line := range lines {
+		var got jsonlResult
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("line %d is not JSON on its own: %v", i, err)
+		}
+		if !reflect.DeepEqual(got, records[i]) {
+			t.Errorf("line %d decodes to %+v, want %+v", i, got, records[i])
+		}
+	}
+}
+
+// writePuzzleFile saves g's starting letters as a puzzle file and returns its path.
+func writePuzzleFile(t *testing.T, g *game.PuzzleGame) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "puzzle.txt")
+	if err := os.WriteFile(path, []byte(g.InitialPuzzleString()+"\n"), 0o644); err != nil {
+		t.Fatalf("writing puzzle file: %v", err)
+	}
+	return path
+}
+
+func TestRunVerify(t *testing.T) {
+	g := exampleGame(t, "hdklrsy")
+	s := solver.NewPuzzleSolver(g)
+	s.Verbosity = solver.Quiet
+	moves, score := s.SolveMonteCarlo(300)
+	path := writePuzzleFile(t, g)
+
+	var out bytes.Buffer
+	if status := runVerify([]string{path, game.EncodeMoves(moves)}, &out); status != 0 {
+		t.Fatalf("runVerify of the solver's solution exited %d:\n%s", status, out.String())
+	}
+	if want := fmt.Sprintf("Score: %d", score); !strings.Contains(out.String(), want) {
+		t.Errorf("output does not report %q:\n%s", want, out.String())
+	}
+
+	// G1 is buried under the whole pyramid, so smashing it is never legal this early
+	const tamperedIndex = 1
+	tampered := append([]game.Move{}, moves...)
+	tampered[tamperedIndex] = game.Move{Source: "G1", Destination: "SMASH"}
+	out.Reset()
+	if status := runVerify([]string{path, game.EncodeMoves(tampered)}, &out); status != 1 {
+		t.Errorf("runVerify of a tampered solution exited %d, want 1", status)
+	}
+	if want := fmt.Sprintf("move %d (G1-SMASH)", tamperedIndex); !strings.Contains(out.String(), want) {
+		t.Errorf("output does not name %q:\n%s", want, out.String())
+	}
+}
+
+func TestGetSegmentedDrawPileInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []int
+	}{
+		{"partial last segment", "asd\nfgh\nj\n", []int{1, 2, 3, 4, 5, 6, 7}},
+		{"empty line stops", "asd\n\nfgh\n", []int{1, 2, 3}},
+		{"bad segment is asked again", "asd\nzzz\nasdf\nfgh\n\n", []int{1, 2, 3, 4, 5, 6}},
+		{"no final newline", "asd\nfg", []int{1, 2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getSegmentedDrawPileInput(bufio.NewReader(strings.NewReader(tt.input)))
+			if err != nil {
+				t.Fatalf("getSegmentedDrawPileInput: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("draw pile = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := getSegmentedDrawPileInput(bufio.NewReader(strings.NewReader(""))); err == nil {
+		t.Error("empty input did not fail")
+	}
+}
+
+func TestFormatSolutionLetters(t *testing.T) {
+	g := exampleGame(t, "hdklrsy")
+	moves := []game.Move{{Source: "A1", Destination: "A5"}} // 12 (y) with 11 (t)
+
+	labeled := formatSolution(moves, 0, g)
+	for _, want := range []string{"A1(y=12)", "A5(t=11)"} {
+		if !strings.Contains(labeled, want) {
+			t.Errorf("labeled solution does not contain %q:\n%s", want, labeled)
+		}
+	}
+	if plain := formatSolution(moves, 0, nil); strings.Contains(plain, "(y=12)") {
+		t.Errorf("solution without a start game is labeled:\n%s", plain)
+	}
+	if got := stoneLabel(g, "A1"); got != "A1(y=12)" {
+		t.Errorf("stoneLabel before the move = %q, want %q", got, "A1(y=12)")
+	}
+	g.MakeMove("A1", "A5")
+	if got := stoneLabel(g, "A1"); got != "A1" {
+		t.Errorf("stoneLabel of a cleared position = %q, want %q", got, "A1")
+	}
+}
+
+func TestCompactSolutionRoundTrips(t *testing.T) {
+	g := exampleGame(t, "hdklrsy")
+	s := solver.NewPuzzleSolver(g)
+	s.Verbosity = solver.Quiet
+	moves, score := s.SolveMonteCarlo(300)
+
+	decoded, err := game.DecodeMoves(game.EncodeMoves(moves))
+	if err != nil {
+		t.Fatalf("DecodeMoves of the compact solution: %v", err)
+	}
+	if len(decoded) != len(moves) {
+		t.Fatalf("compact solution decodes to %d moves, want %d", len(decoded), len(moves))
+	}
+	final, err := solver.Replay(g, decoded)
+	if err != nil {
+		t.Fatalf("decoded solution does not replay: %v", err)
+	}
+	if final.CalculateScore() != score {
+		t.Errorf("decoded solution scores %d, want %d", final.CalculateScore(), score)
+	}
+}
+
+func TestVerifySolutionFlagsMismatch(t *testing.T) {
+	g := exampleGame(t, "hdklrsy")
+	moves, err := game.DecodeMoves("A1-A5 A3-A7")
+	if err != nil {
+		t.Fatalf("DecodeMoves: %v", err)
+	}
+	final, err := solver.Replay(g, moves)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	score := final.CalculateScore()
+
+	if err := verifySolution(g, moves, score); err != nil {
+		t.Errorf("verifySolution of a correct score: %v", err)
+	}
+	if err := verifySolution(g, moves, score+50); err == nil {
+		t.Error("verifySolution accepted a wrong score")
+	}
+	illegal := []game.Move{{Source: "G1", Destination: "SMASH"}}
+	if err := verifySolution(g, illegal, 0); err == nil {
+		t.Error("verifySolution accepted an illegal move")
+	}
+}
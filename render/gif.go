@@ -0,0 +1,171 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"io"
+
+	"pyramid_solver_go_local/game"
+	"pyramid_solver_go_local/utils"
+)
+
+// GIF frame timing, in hundredths of a second.
+const (
+	frameDelay     = 60
+	lastFrameDelay = 300
+	digitScale     = 3 // Each glyph pixel is drawn as a digitScale x digitScale block
+)
+
+// Palette indices of the GIF frames, using the colours of RenderSVG.
+const (
+	colorBackground uint8 = iota
+	colorStone
+	colorOutline
+	colorAccessible
+	colorCleared
+	colorFading
+	colorFadingInk
+	colorInk
+)
+
+var gifPalette = color.Palette{
+	colorBackground: color.RGBA{0xf4, 0xef, 0xe6, 0xff},
+	colorStone:      color.RGBA{0xff, 0xfa, 0xf0, 0xff},
+	colorOutline:    color.RGBA{0x6b, 0x63, 0x57, 0xff},
+	colorAccessible: color.RGBA{0xd9, 0x48, 0x0f, 0xff},
+	colorCleared:    color.RGBA{0xe6, 0xe0, 0xd6, 0xff}, // #cfc8bb at 35% over the background
+	colorFading:     color.RGBA{0xec, 0xe6, 0xdc, 0xff},
+	colorFadingInk:  color.RGBA{0xb8, 0xb0, 0xa3, 0xff},
+	colorInk:        color.RGBA{0x00, 0x00, 0x00, 0xff},
+}
+
+// digitGlyphs are 3x5 bitmaps of the digits 0-9, one row per string.
+var digitGlyphs = [10][5]string{
+	{"###", "#.#", "#.#", "#.#", "###"},
+	{".#.", "##.", ".#.", ".#.", "###"},
+	{"###", "..#", "###", "#..", "###"},
+	{"###", "..#", "###", "..#", "###"},
+	{"#.#", "#.#", "###", "..#", "..#"},
+	{"###", "#..", "###", "..#", "###"},
+	{"###", "#..", "###", "#.#", "###"},
+	{"###", "..#", "..#", "..#", "..#"},
+	{"###", "#.#", "###", "#.#", "###"},
+	{"###", "#.#", "###", "..#", "###"},
+}
+
+// RenderGIF writes moves as an animated GIF, laid out like RenderSVG: one frame for the
+// starting state and one after each move that clears a stone. Stones cleared by a frame's move
+// are drawn fading out, and are gone from the next frame. g is not modified; a move that is not
+// legal when played is an error.
+func RenderGIF(g *game.PuzzleGame, moves []game.Move, w io.Writer) error {
+	replayed := g.DeepCopy()
+	anim := &gif.GIF{}
+	addFrame := func(fading [][]int) {
+		anim.Image = append(anim.Image, drawFrame(replayed, fading))
+		anim.Delay = append(anim.Delay, frameDelay)
+	}
+
+	addFrame(nil)
+	for i, move := range moves {
+		if !replayed.IsLegalMove(move) {
+			return fmt.Errorf("move %d (%s) is not legal", i, game.EncodeMoves([]game.Move{move}))
+		}
+		before := replayed.Grid()
+		if replayed.MakeMove(move.Source, move.Destination) {
+			addFrame(before)
+		}
+	}
+	anim.Delay[len(anim.Delay)-1] = lastFrameDelay
+	return gif.EncodeAll(w, anim)
+}
+
+// drawFrame rasterizes g. If before is non-nil, positions holding a stone in before that are
+// empty in g are drawn fading, with their old value.
+func drawFrame(g *game.PuzzleGame, before [][]int) *image.Paletted {
+	width := 2*margin + 2*stoneRadius + (utils.PyramidRowSizes[0]-1)*stoneSpacing
+	pyramidHeight := 2*stoneRadius + (game.MaxPyramidRows-1)*rowSpacing
+	height := 2*margin + pyramidHeight + rowSpacing + 2*stoneRadius
+	img := image.NewPaletted(image.Rect(0, 0, width, height), gifPalette)
+	// The zero index is the background, so a new image is already filled with it
+
+	for rowIdx, row := range g.Grid() {
+		for colIdx, stone := range row {
+			x, y := stoneCenter(rowIdx, colIdx)
+			cx, cy := int(x), int(y)
+			switch {
+			case stone != -1:
+				outline := colorOutline
+				if g.IsAccessible(rowIdx, colIdx) {
+					outline = colorAccessible
+				}
+				fillCircle(img, cx, cy, stoneRadius, outline)
+				fillCircle(img, cx, cy, stoneRadius-2, colorStone)
+				drawNumber(img, cx, cy, stone, colorInk)
+			case before != nil && before[rowIdx][colIdx] != -1:
+				fillCircle(img, cx, cy, stoneRadius, colorFading)
+				drawNumber(img, cx, cy, before[rowIdx][colIdx], colorFadingInk)
+			default:
+				fillCircle(img, cx, cy, stoneRadius, colorCleared)
+			}
+		}
+	}
+
+	y := margin + pyramidHeight + rowSpacing
+	drawSlot(img, g.HoldValue(), margin+stoneRadius, y)
+	drawSlot(img, g.GetCurrentDrawStone(), width-margin-stoneRadius, y)
+	return img
+}
+
+// drawSlot draws the hold or draw slot as a square centred on (cx, cy), empty when stone is -1.
+func drawSlot(img *image.Paletted, stone, cx, cy int) {
+	fillRect(img, cx-stoneRadius, cy-stoneRadius, cx+stoneRadius, cy+stoneRadius, colorOutline)
+	fillRect(img, cx-stoneRadius+1, cy-stoneRadius+1, cx+stoneRadius-1, cy+stoneRadius-1, colorStone)
+	if stone != -1 {
+		drawNumber(img, cx, cy, stone, colorInk)
+	}
+}
+
+// fillCircle fills the circle of radius r centred on (cx, cy).
+func fillCircle(img *image.Paletted, cx, cy, r int, c uint8) {
+	for y := cy - r; y <= cy+r; y++ {
+		for x := cx - r; x <= cx+r; x++ {
+			if dx, dy := x-cx, y-cy; dx*dx+dy*dy <= r*r {
+				img.SetColorIndex(x, y, c)
+			}
+		}
+	}
+}
+
+// fillRect fills the rectangle from (x0, y0) to (x1, y1), both inclusive.
+func fillRect(img *image.Paletted, x0, y0, x1, y1 int, c uint8) {
+	for y := y0; y <= y1; y++ {
+		for x := x0; x <= x1; x++ {
+			img.SetColorIndex(x, y, c)
+		}
+	}
+}
+
+// drawNumber draws n centred on (cx, cy) with the digit glyphs.
+func drawNumber(img *image.Paletted, cx, cy, n int, c uint8) {
+	digits := fmt.Sprint(n)
+	glyphWidth, gap := 3*digitScale, digitScale
+	totalWidth := len(digits)*glyphWidth + (len(digits)-1)*gap
+	left, top := cx-totalWidth/2, cy-5*digitScale/2
+	for i, digit := range digits {
+		if digit < '0' || digit > '9' {
+			continue
+		}
+		glyph := digitGlyphs[digit-'0']
+		x0 := left + i*(glyphWidth+gap)
+		for row, bits := range glyph {
+			for col, bit := range bits {
+				if bit == '#' {
+					x, y := x0+col*digitScale, top+row*digitScale
+					fillRect(img, x, y, x+digitScale-1, y+digitScale-1, c)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package render
+
+import (
+	"bytes"
+	"image/gif"
+	"testing"
+
+	"pyramid_solver_go_local/game"
+)
+
+func TestRenderGIFFrames(t *testing.T) {
+	g := examplePuzzle(t)
+	moves, err := game.DecodeMoves("A1-A5 DRAW A3-A7") // Two clearing moves and a draw
+	if err != nil {
+		t.Fatalf("DecodeMoves: %v", err)
+	}
+	var out bytes.Buffer
+	if err := RenderGIF(g, moves, &out); err != nil {
+		t.Fatalf("RenderGIF: %v", err)
+	}
+	anim, err := gif.DecodeAll(&out)
+	if err != nil {
+		t.Fatalf("output is not a GIF: %v", err)
+	}
+	if len(anim.Image) != 3 {
+		t.Errorf("GIF has %d frames, want one for the start and one per clearing move: 3", len(anim.Image))
+	}
+	if g.PyramidValue(0, 0) == -1 {
+		t.Error("RenderGIF modified the game")
+	}
+
+	if err := RenderGIF(g, []game.Move{{Source: "G1", Destination: "SMASH"}}, &bytes.Buffer{}); err == nil {
+		t.Error("RenderGIF accepted an illegal move")
+	}
+}
@@ -0,0 +1,94 @@
+// Package render draws puzzle states for display outside the terminal.
+package render
+
+import (
+	"fmt"
+	"io"
+
+	"pyramid_solver_go_local/game"
+	"pyramid_solver_go_local/utils"
+)
+
+// Layout of the SVG drawing, in user units.
+const (
+	stoneRadius  = 20
+	stoneSpacing = 46 // Horizontal distance between neighbouring stones in a row
+	rowSpacing   = 40
+	margin       = 30
+)
+
+// errWriter remembers the first write error so drawing code can stay linear.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
+
+// stoneCenter returns the centre of the stone at rowIdx/colIdx. Row G (the apex) is drawn at
+// the top, matching PrintState.
+func stoneCenter(rowIdx, colIdx int) (float64, float64) {
+	x := margin + stoneRadius + float64(colIdx)*stoneSpacing + float64(rowIdx)*stoneSpacing/2
+	y := margin + stoneRadius + float64(game.MaxPyramidRows-1-rowIdx)*rowSpacing
+	return x, y
+}
+
+// RenderSVG writes the pyramid as an SVG image: one circle per position labelled with its
+// value, cleared positions dimmed, accessible ones outlined, and the hold and current draw
+// stone beneath the pyramid.
+func RenderSVG(g *game.PuzzleGame, w io.Writer) error {
+	grid := g.Grid()
+	width := 2*margin + 2*stoneRadius + (utils.PyramidRowSizes[0]-1)*stoneSpacing
+	pyramidHeight := 2*stoneRadius + (game.MaxPyramidRows-1)*rowSpacing
+	height := 2*margin + pyramidHeight + rowSpacing + 2*stoneRadius
+
+	ew := &errWriter{w: w}
+	ew.printf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, height, width, height)
+	ew.printf(`<rect width="100%%" height="100%%" fill="#f4efe6"/>` + "\n")
+
+	for rowIdx, row := range grid {
+		for colIdx, stone := range row {
+			x, y := stoneCenter(rowIdx, colIdx)
+			pos, _ := utils.IndicesToString(rowIdx, colIdx)
+			if stone == -1 {
+				ew.printf(`<circle class="stone cleared" data-pos="%s" cx="%.1f" cy="%.1f" r="%d" fill="#cfc8bb" opacity="0.35"/>`+"\n",
+					pos, x, y, stoneRadius)
+				continue
+			}
+			stroke := "#6b6357"
+			strokeWidth := 1
+			if g.IsAccessible(rowIdx, colIdx) {
+				stroke, strokeWidth = "#d9480f", 3
+			}
+			ew.printf(`<circle class="stone" data-pos="%s" cx="%.1f" cy="%.1f" r="%d" fill="#fffaf0" stroke="%s" stroke-width="%d"/>`+"\n",
+				pos, x, y, stoneRadius, stroke, strokeWidth)
+			ew.printf(`<text x="%.1f" y="%.1f" text-anchor="middle" dominant-baseline="central" font-family="sans-serif" font-size="14">%d</text>`+"\n",
+				x, y, stone)
+		}
+	}
+
+	y := float64(margin + pyramidHeight + rowSpacing)
+	writeSlot(ew, "HOLD", g.HoldValue(), float64(margin+stoneRadius), y)
+	writeSlot(ew, "DRW1", g.GetCurrentDrawStone(), float64(width-margin-stoneRadius), y)
+
+	ew.printf("</svg>\n")
+	return ew.err
+}
+
+// writeSlot draws the hold or draw slot as a labelled square, empty when stone is -1.
+func writeSlot(ew *errWriter, label string, stone int, x, y float64) {
+	ew.printf(`<rect class="slot" x="%.1f" y="%.1f" width="%d" height="%d" fill="#fffaf0" stroke="#6b6357"/>`+"\n",
+		x-stoneRadius, y-stoneRadius, 2*stoneRadius, 2*stoneRadius)
+	ew.printf(`<text x="%.1f" y="%.1f" text-anchor="middle" font-family="sans-serif" font-size="10">%s</text>`+"\n",
+		x, y-stoneRadius-4, label)
+	if stone != -1 {
+		ew.printf(`<text x="%.1f" y="%.1f" text-anchor="middle" dominant-baseline="central" font-family="sans-serif" font-size="14">%d</text>`+"\n",
+			x, y, stone)
+	}
+}
@@ -0,0 +1,56 @@
+package render
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"pyramid_solver_go_local/game"
+)
+
+// examplePuzzle returns the example puzzle of main.go.
+func examplePuzzle(t *testing.T) *game.PuzzleGame {
+	t.Helper()
+	g := game.NewPuzzleGame()
+	pyramid := []int{12, 10, 11, 6, 11, 7, 12, 11, 5, 1, 4, 1, 4, 5, 10, 8, 11, 9, 7, 2, 9, 6, 2, 13, 9, 10, 12, 13}
+	drawPile := []int{6, 3, 8, 9, 3, 10, 2, 13, 6, 7, 1, 13, 12, 4, 1, 2, 3, 8, 5, 3, 5, 7, 3, 8}
+	if err := g.SetupCustomGame(pyramid, drawPile); err != nil {
+		t.Fatalf("SetupCustomGame: %v", err)
+	}
+	return g
+}
+
+// countStoneElements parses svg as XML and counts the elements whose class list has "stone".
+func countStoneElements(t *testing.T, svg []byte) int {
+	t.Helper()
+	decoder := xml.NewDecoder(bytes.NewReader(svg))
+	stones := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return stones
+		}
+		if err != nil {
+			t.Fatalf("SVG is not well-formed XML: %v", err)
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			for _, attr := range start.Attr {
+				if attr.Name.Local == "class" && strings.Contains(" "+attr.Value+" ", " stone ") {
+					stones++
+				}
+			}
+		}
+	}
+}
+
+func TestRenderSVGFullBoard(t *testing.T) {
+	var buf bytes.Buffer
+	if err := RenderSVG(examplePuzzle(t), &buf); err != nil {
+		t.Fatalf("RenderSVG: %v", err)
+	}
+	if got := countStoneElements(t, buf.Bytes()); got != game.TotalPyramidStones {
+		t.Errorf("SVG has %d stone elements, want %d", got, game.TotalPyramidStones)
+	}
+}
@@ -0,0 +1,179 @@
+package solver
+
+import (
+	"pyramid_solver_go_local/game"
+	"pyramid_solver_go_local/utils"
+)
+
+// ClearHeatmap replays each result on a copy of the game it was played from, its Start or else
+// the solver's puzzle, and returns, for every pyramid position, the average point in the
+// solution at which it was cleared: 0 means it was always cleared by the first move, 1 by the
+// last. A position a result never clears counts as 1 for that result, so bottlenecks stand out.
+// Positions empty from the start stay 0.
+func (s *PuzzleSolver) ClearHeatmap(results []Result) [game.MaxPyramidRows][game.MaxPyramidCols]float64 {
+	var heatmap [game.MaxPyramidRows][game.MaxPyramidCols]float64
+	if len(results) == 0 {
+		return heatmap
+	}
+
+	for _, result := range results {
+		var clearedAt [game.MaxPyramidRows][game.MaxPyramidCols]float64
+		var cleared [game.MaxPyramidRows][game.MaxPyramidCols]bool
+		replayed := s.originalGame.DeepCopy()
+		if result.Start != nil {
+			replayed = result.Start.DeepCopy()
+		}
+		for i, move := range result.Moves {
+			replayed.MakeMove(move.Source, move.Destination)
+			progress := 0.0
+			if len(result.Moves) > 1 {
+				progress = float64(i) / float64(len(result.Moves)-1)
+			}
+			for rowIdx, rowSize := range utils.PyramidRowSizes {
+				for colIdx := 0; colIdx < rowSize; colIdx++ {
+					if !cleared[rowIdx][colIdx] && replayed.PyramidValue(rowIdx, colIdx) == -1 {
+						cleared[rowIdx][colIdx] = true
+						clearedAt[rowIdx][colIdx] = progress
+					}
+				}
+			}
+		}
+
+		for rowIdx, rowSize := range utils.PyramidRowSizes {
+			for colIdx := 0; colIdx < rowSize; colIdx++ {
+				switch {
+				case s.originalGame.PyramidValue(rowIdx, colIdx) == -1:
+					// Empty before the first move: contributes 0
+				case cleared[rowIdx][colIdx]:
+					heatmap[rowIdx][colIdx] += clearedAt[rowIdx][colIdx]
+				default:
+					heatmap[rowIdx][colIdx] += 1
+				}
+			}
+		}
+	}
+
+	for rowIdx, rowSize := range utils.PyramidRowSizes {
+		for colIdx := 0; colIdx < rowSize; colIdx++ {
+			heatmap[rowIdx][colIdx] /= float64(len(results))
+		}
+	}
+	return heatmap
+}
+
+// MinRedrawsEstimate estimates the fewest redraws g needs by playing it out greedily: any
+// clearing move is taken as soon as one is available, and the draw pile is only advanced when
+// none is. It returns the redraws that greedy playout used before clearing the pyramid or
+// stalling, so it is a heuristic, not a proven lower bound. g is not modified.
+func MinRedrawsEstimate(g *game.PuzzleGame) int {
+	playout := g.DeepCopy()
+	tempGame := g.DeepCopy()
+	idleDrawLimit := g.DrawStonesRemaining() + 1
+	idleDraws := 0
+
+	for !playout.IsSolved() && idleDraws <= idleDrawLimit {
+		var next *game.Move
+		canDraw := false
+		for _, move := range playout.LegalMoves() {
+			if move.Source == "DRAW" {
+				canDraw = true
+				continue
+			}
+			tempGame.Reset(playout)
+			if tempGame.MakeMove(move.Source, move.Destination) {
+				next = &move
+				break
+			}
+		}
+		switch {
+		case next != nil:
+			playout.MakeMove(next.Source, next.Destination)
+			idleDraws = 0
+		case canDraw:
+			playout.MakeMove("DRAW", "DRAW")
+			idleDraws++
+		default:
+			return playout.Redraws()
+		}
+	}
+	return playout.Redraws()
+}
+
+// MatchHistogram replays moves on a copy of g and counts the matches made with each pairing,
+// keyed by the pair's lower value: 1 counts 1-2 matches, 11 counts 11-12. Smashes are not
+// matches and are not counted. Replay stops at the first move that is not legal, and nothing is
+// counted if g's draw pile has unknown stones; pass the solver's LastStart instead.
+func MatchHistogram(g *game.PuzzleGame, moves []game.Move) map[int]int {
+	histogram := make(map[int]int)
+	if checkKnownDrawPile(g) != nil {
+		return histogram
+	}
+	replayed := g.DeepCopy()
+	for _, move := range moves {
+		if !replayed.IsLegalMove(move) {
+			break
+		}
+		source, destination := replayed.LocationValue(move.Source), replayed.LocationValue(move.Destination)
+		if replayed.MakeMove(move.Source, move.Destination) && move.Destination != "SMASH" {
+			histogram[min(source, destination)]++
+		}
+	}
+	return histogram
+}
+
+// Phase is a run of consecutive moves of a solution with a common character, moves[Start:End].
+type Phase struct {
+	Label      string
+	Start, End int
+}
+
+// Phase labels returned by PhaseSolution.
+const (
+	PhaseSmash   = "Smash the 13s"
+	PhaseClear   = "Clear the pyramid"
+	PhaseCleanup = "Clean up the draw pile"
+)
+
+// PhaseSolution splits moves, a solution for g, into up to three phases for write-ups: the
+// opening smashes, the main clearing of the pyramid, and a cleanup of the moves that no longer
+// touch the pyramid, being played on the draw pile and HOLD after its last stone is taken or
+// the pyramid is solved. Empty phases are left out, so the phases returned always cover moves
+// exactly, in order. For a solution the solver found on sampled unknown draw stones, pass its
+// LastStart as g, or the cleanup may start in the wrong place.
+func PhaseSolution(g *game.PuzzleGame, moves []game.Move) []Phase {
+	openingEnd := 0
+	for openingEnd < len(moves) && moves[openingEnd].Destination == "SMASH" {
+		openingEnd++
+	}
+
+	// The cleanup starts after the last move that touches the pyramid while it still has stones
+	cleanupStart := openingEnd
+	replayed := g.DeepCopy()
+	for i, move := range moves {
+		if replayed.IsSolved() {
+			break
+		}
+		if isPyramidPosition(move.Source) || isPyramidPosition(move.Destination) {
+			cleanupStart = max(cleanupStart, i+1)
+		}
+		replayed.MakeMove(move.Source, move.Destination)
+	}
+
+	phases := []Phase{}
+	for _, phase := range []Phase{
+		{Label: PhaseSmash, Start: 0, End: openingEnd},
+		{Label: PhaseClear, Start: openingEnd, End: cleanupStart},
+		{Label: PhaseCleanup, Start: cleanupStart, End: len(moves)},
+	} {
+		if phase.End > phase.Start {
+			phases = append(phases, phase)
+		}
+	}
+	return phases
+}
+
+// isPyramidPosition reports whether location names a pyramid position such as "C2".
+func isPyramidPosition(location string) bool {
+	_, _, err := utils.StringToIndices(location)
+	return err == nil
+}
@@ -0,0 +1,105 @@
+package solver
+
+import (
+	"testing"
+
+	"pyramid_solver_go_local/game"
+)
+
+func TestClearHeatmap(t *testing.T) {
+	s := quietSolver(examplePuzzle(t))
+	early, _ := game.DecodeMoves("A1-A5 A3-A7 A4-B2")
+	late, _ := game.DecodeMoves("A3-A7 A1-A5 A4-B2")
+	heatmap := s.ClearHeatmap([]Result{{Moves: early}, {Moves: late}})
+
+	for rowIdx, row := range heatmap {
+		for colIdx, heat := range row {
+			if heat < 0 || heat > 1 {
+				t.Errorf("heatmap[%d][%d] = %v, outside [0, 1]", rowIdx, colIdx, heat)
+			}
+		}
+	}
+	a1, a3, a4, g1 := heatmap[0][0], heatmap[0][2], heatmap[0][3], heatmap[6][0]
+	if a1 != a3 {
+		t.Errorf("A1 (%v) and A3 (%v) are each cleared first once and second once, want equal", a1, a3)
+	}
+	if a1 >= a4 {
+		t.Errorf("A1 (%v) should be cleared earlier than A4 (%v), which is always cleared last", a1, a4)
+	}
+	if g1 != 1 {
+		t.Errorf("G1 is never cleared, heat %v, want 1", g1)
+	}
+}
+
+func TestMinRedrawsEstimateOnePass(t *testing.T) {
+	// A1 is a 1 and A2 a 3 under a pyramid of 13s; the draw pile holds their partners in the
+	// order they come up, so greedy play never needs to go round the pile again
+	pyramid := make([]int, game.TotalPyramidStones)
+	for i := range pyramid {
+		pyramid[i] = 13
+	}
+	pyramid[0], pyramid[1] = 1, 3
+	g := game.NewPuzzleGame()
+	if err := g.SetupCustomGame(pyramid, []int{7, 4, 2, 7, 7, 7}); err != nil {
+		t.Fatalf("SetupCustomGame: %v", err)
+	}
+	if got := MinRedrawsEstimate(g); got != 0 {
+		t.Errorf("MinRedrawsEstimate() = %d, want 0", got)
+	}
+	if g.Redraws() != 0 || g.IsSolved() {
+		t.Error("MinRedrawsEstimate modified the game")
+	}
+}
+
+func TestMatchHistogramCountsMatchesOnly(t *testing.T) {
+	moves, _ := seedSolution(t) // Draws, parks stones in HOLD and smashes along the way
+	final, err := Replay(examplePuzzle(t), moves)
+	if err != nil {
+		t.Fatalf("seed solution does not replay: %v", err)
+	}
+	smashes := 0
+	for _, move := range moves {
+		if move.Destination == "SMASH" {
+			smashes++
+		}
+	}
+
+	histogram := MatchHistogram(examplePuzzle(t), moves)
+	total := 0
+	for low, n := range histogram {
+		if low < 1 || low > 11 || low%2 == 0 {
+			t.Errorf("histogram has %d matches keyed %d, not the lower value of a pair", n, low)
+		}
+		total += n
+	}
+	// The breakdown scores 50 for every match and every smash
+	clears := final.ScoreBreakdown().Matches / 50
+	if want := clears - smashes; total != want {
+		t.Errorf("histogram counts %d matches, want %d (%d clears less %d smashes)", total, want, clears, smashes)
+	}
+}
+
+func TestPhaseSolutionPartitionsMoves(t *testing.T) {
+	seed, _ := seedSolution(t)
+	for name, moves := range map[string][]game.Move{"solver solution": seed, "no moves": nil} {
+		phases := PhaseSolution(examplePuzzle(t), moves)
+		next := 0
+		for _, phase := range phases {
+			if phase.Start != next || phase.End <= phase.Start {
+				t.Errorf("%s: phase %q covers [%d, %d), want a non-empty phase from %d", name, phase.Label, phase.Start, phase.End, next)
+			}
+			next = phase.End
+		}
+		if next != len(moves) {
+			t.Errorf("%s: phases end at %d, want all %d moves covered", name, next, len(moves))
+		}
+	}
+
+	// Smashing the 13s first, then clearing, then drawing on a solved pyramid
+	g := uniformPuzzle(t, 13, []int{1, 2, 3, 4, 5, 6})
+	moves := append(smashAllMoves(g), game.Move{Source: "DRAW", Destination: "DRAW"})
+	phases := PhaseSolution(g, moves)
+	if len(phases) != 2 || phases[0].Label != PhaseSmash || phases[1].Label != PhaseCleanup {
+		t.Errorf("phases of smashes then a draw = %+v, want smash and cleanup phases", phases)
+	}
+}
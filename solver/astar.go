@@ -0,0 +1,107 @@
+package solver
+
+import (
+	"container/heap"
+
+	"pyramid_solver_go_local/game"
+)
+
+// searchNode is a state in the best-first search, linked to the state it was reached from.
+type searchNode struct {
+	game   *game.PuzzleGame
+	parent *searchNode
+	move   game.Move // Move that led from parent to this node
+	depth  int
+	bound  int // MaxPossibleScore of the state
+	// estimatedLength is depth plus ceil(remaining pyramid stones / 2), the fewest moves that
+	// could still clear the board since a match removes at most two stones.
+	estimatedLength int
+}
+
+// path returns the moves from the root to n.
+func (n *searchNode) path() []game.Move {
+	moves := make([]game.Move, n.depth)
+	for node := n; node.parent != nil; node = node.parent {
+		moves[node.depth-1] = node.move
+	}
+	return moves
+}
+
+// nodeQueue is a max-heap on bound, preferring shorter estimated solutions on ties.
+type nodeQueue []*searchNode
+
+func (q nodeQueue) Len() int { return len(q) }
+func (q nodeQueue) Less(i, j int) bool {
+	if q[i].bound != q[j].bound {
+		return q[i].bound > q[j].bound
+	}
+	return q[i].estimatedLength < q[j].estimatedLength
+}
+func (q nodeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *nodeQueue) Push(x interface{}) { *q = append(*q, x.(*searchNode)) }
+func (q *nodeQueue) Pop() interface{} {
+	old := *q
+	node := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return node
+}
+
+// SolveAStar runs an A*-style best-first search that maximizes score. Each state is ranked by
+// MaxPossibleScore, an admissible (never too low) bound on the final score reachable from it,
+// so once the best unexpanded bound drops to the best score seen the search has proved that
+// score optimal. In practice the bound is loose and the search usually stops at NodeBudget
+// expansions, returning the best state found. Revisited states are skipped using Hash, as far
+// as MaxTableEntries lets them be remembered.
+func (s *PuzzleSolver) SolveAStar() ([]game.Move, int) {
+	root := &searchNode{game: s.originalGame.DeepCopy()}
+	root.bound = root.game.MaxPossibleScore()
+	root.estimatedLength = (remainingStones(root.game) + 1) / 2
+
+	bestNode, bestScore := root, root.game.CalculateScore()
+	closed := newStateTable(s.MaxTableEntries)
+	queue := &nodeQueue{root}
+	scratch := s.originalGame.DeepCopy()
+	order := s.moveOrder()
+
+	for expanded := 0; queue.Len() > 0 && expanded < s.NodeBudget; {
+		node := heap.Pop(queue).(*searchNode)
+		if node.bound <= bestScore {
+			break // Nothing left in the queue can beat the best score
+		}
+		hash := node.game.Hash()
+		if closed.contains(hash) {
+			continue
+		}
+		closed.add(hash)
+		expanded++
+
+		for _, move := range order(node.game, node.game.LegalMoves()) {
+			scratch.Reset(node.game)
+			scratch.MakeMove(move.Source, move.Destination)
+			if closed.contains(scratch.Hash()) {
+				continue
+			}
+
+			child := &searchNode{
+				game:   scratch.DeepCopy(),
+				parent: node,
+				move:   move,
+				depth:  node.depth + 1,
+				bound:  scratch.MaxPossibleScore(),
+			}
+			child.estimatedLength = child.depth + (remainingStones(scratch)+1)/2
+
+			score := child.game.CalculateScore()
+			if score > bestScore || (score == bestScore && child.depth < bestNode.depth) {
+				bestNode, bestScore = child, score
+			}
+			heap.Push(queue, child)
+		}
+	}
+	return canonicalMoves(bestNode.path()), bestScore
+}
+
+// remainingStones returns the number of stones left in the pyramid.
+func remainingStones(g *game.PuzzleGame) int {
+	return game.TotalPyramidStones - clearedCount(g)
+}
@@ -0,0 +1,35 @@
+package solver
+
+import (
+	"testing"
+
+	"pyramid_solver_go_local/game"
+)
+
+func TestSolveAStarClearsSmallBoard(t *testing.T) {
+	// A pyramid of 13s except for two pairs at A1 to A4; every stone can be cleared
+	stones := []int{1, 2, 3, 4}
+	for len(stones) < game.TotalPyramidStones {
+		stones = append(stones, 13)
+	}
+	g := game.NewPuzzleGame()
+	if err := g.SetupCustomGame(stones, []int{5, 6, 7}); err != nil {
+		t.Fatalf("SetupCustomGame: %v", err)
+	}
+
+	s := quietSolver(g)
+	moves, score := s.SolveAStar()
+	final, err := Replay(g, moves)
+	if err != nil {
+		t.Fatalf("A* solution does not replay: %v", err)
+	}
+	if !final.IsSolved() {
+		t.Fatalf("A* solution %s leaves the pyramid uncleared", game.EncodeMoves(moves))
+	}
+	if final.CalculateScore() != score {
+		t.Errorf("A* solution replays to %d, reported %d", final.CalculateScore(), score)
+	}
+	if _, mcScore := quietSolver(g).SolveMonteCarlo(2000); mcScore > score {
+		t.Errorf("Monte Carlo found %d, better than A*'s %d", mcScore, score)
+	}
+}
@@ -0,0 +1,66 @@
+package solver
+
+import (
+	"sync"
+
+	"pyramid_solver_go_local/game"
+)
+
+// cachedSolution is the value stored in a CachingSolver's cache.
+type cachedSolution struct {
+	moves []game.Move
+	score int
+}
+
+// CachingSolver memoizes another Solver by the hash of the puzzle's starting state, which
+// includes the rule settings, so solving an identical puzzle under the same rules again returns
+// the earlier result without searching. It is safe for concurrent use.
+type CachingSolver struct {
+	inner Solver
+	cache sync.Map // uint64 -> cachedSolution
+}
+
+// NewCachingSolver wraps inner with a result cache.
+func NewCachingSolver(inner Solver) *CachingSolver {
+	return &CachingSolver{inner: inner}
+}
+
+// Solve returns the cached result for g's state if there is one, and otherwise solves it with
+// the wrapped Solver and caches the result. The iteration count is not part of the key.
+func (c *CachingSolver) Solve(g *game.PuzzleGame, iterations int) ([]game.Move, int) {
+	key := g.Hash()
+	if cached, ok := c.cache.Load(key); ok {
+		solution := cached.(cachedSolution)
+		return copyMoves(solution.moves), solution.score
+	}
+
+	moves, score := c.inner.Solve(g, iterations)
+	c.cache.Store(key, cachedSolution{moves: copyMoves(moves), score: score})
+	return moves, score
+}
+
+// DedupPuzzles drops repeated puzzles from a batch before solving. It returns the unique
+// puzzles in order of first appearance, and a map from each puzzle's Hash to the indices in
+// games where it occurs, so results can be fanned back out to every copy.
+func DedupPuzzles(games []*game.PuzzleGame) ([]*game.PuzzleGame, map[uint64][]int) {
+	unique := []*game.PuzzleGame{}
+	indices := make(map[uint64][]int)
+	for i, g := range games {
+		key := g.Hash()
+		if _, seen := indices[key]; !seen {
+			unique = append(unique, g)
+		}
+		indices[key] = append(indices[key], i)
+	}
+	return unique, indices
+}
+
+// copyMoves returns a copy of moves so cached slices are never shared with callers.
+func copyMoves(moves []game.Move) []game.Move {
+	if moves == nil {
+		return nil
+	}
+	copied := make([]game.Move, len(moves))
+	copy(copied, moves)
+	return copied
+}
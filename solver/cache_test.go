@@ -0,0 +1,83 @@
+package solver
+
+import (
+	"reflect"
+	"testing"
+
+	"pyramid_solver_go_local/game"
+)
+
+var _ Solver = MonteCarloSolver{}
+
+// countingSolver is a Solver that counts its calls and returns one fixed move.
+type countingSolver struct {
+	calls int
+}
+
+func (c *countingSolver) Solve(g *game.PuzzleGame, iterations int) ([]game.Move, int) {
+	c.calls++
+	return []game.Move{{Source: "DRAW", Destination: "DRAW"}}, c.calls
+}
+
+func TestCachingSolverReusesResults(t *testing.T) {
+	inner := &countingSolver{}
+	cache := NewCachingSolver(inner)
+
+	firstMoves, firstScore := cache.Solve(examplePuzzle(t), 1)
+	secondMoves, secondScore := cache.Solve(examplePuzzle(t), 1)
+	if inner.calls != 1 {
+		t.Errorf("inner solver called %d times for the same puzzle, want 1", inner.calls)
+	}
+	if secondScore != firstScore || game.EncodeMoves(secondMoves) != game.EncodeMoves(firstMoves) {
+		t.Errorf("cached result %s (%d) differs from the first %s (%d)",
+			game.EncodeMoves(secondMoves), secondScore, game.EncodeMoves(firstMoves), firstScore)
+	}
+
+	secondMoves[0] = game.Move{Source: "A1", Destination: "SMASH"}
+	if thirdMoves, _ := cache.Solve(examplePuzzle(t), 1); thirdMoves[0].Source != "DRAW" {
+		t.Error("changing a returned solution changed the cached one")
+	}
+}
+
+func TestCachingSolverMissesOnChangedRules(t *testing.T) {
+	inner := &countingSolver{}
+	cache := NewCachingSolver(inner)
+	standard := examplePuzzle(t)
+	tweaked := examplePuzzle(t)
+	tweaked.SetScoringRules(game.ScoringRules{LeftoverDrawStone: 100, LeftoverHoldStone: 0})
+
+	cache.Solve(standard, 1)
+	if _, score := cache.Solve(tweaked, 1); score != 2 {
+		t.Errorf("solve under changed scoring rules returned cached score %d, want a fresh solve", score)
+	}
+	if inner.calls != 2 {
+		t.Errorf("inner solver called %d times, want 2", inner.calls)
+	}
+}
+
+func TestDedupPuzzles(t *testing.T) {
+	other := uniformPuzzle(t, 13, []int{1, 2, 3})
+	games := []*game.PuzzleGame{examplePuzzle(t), other, examplePuzzle(t)}
+
+	unique, indices := DedupPuzzles(games)
+	if len(unique) != 2 || unique[0] != games[0] || unique[1] != other {
+		t.Fatalf("DedupPuzzles kept %d puzzles, want the example and the other puzzle in order", len(unique))
+	}
+	if got := indices[games[0].Hash()]; !reflect.DeepEqual(got, []int{0, 2}) {
+		t.Errorf("example puzzle indices = %v, want [0 2]", got)
+	}
+	if got := indices[other.Hash()]; !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("other puzzle indices = %v, want [1]", got)
+	}
+}
+
+func TestDedupPuzzlesKeepsDifferentRules(t *testing.T) {
+	standard := examplePuzzle(t)
+	variant := examplePuzzle(t)
+	variant.SetAllowHoldPlacement(true)
+
+	unique, _ := DedupPuzzles([]*game.PuzzleGame{standard, variant})
+	if len(unique) != 2 {
+		t.Errorf("DedupPuzzles merged puzzles under different rules: %d unique, want 2", len(unique))
+	}
+}
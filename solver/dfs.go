@@ -0,0 +1,109 @@
+package solver
+
+import (
+	"sort"
+
+	"pyramid_solver_go_local/game"
+)
+
+// FindAnySolution runs a depth-first search for any sequence that clears the pyramid, ignoring
+// score, which is much cheaper than a full Monte Carlo solve when all that matters is whether a
+// puzzle can be won. Moves are tried in MoveOrder, by default clearing moves first.
+// States already visited (by Hash) are skipped, as far as MaxTableEntries lets them be
+// remembered, and a branch is abandoned after more consecutive draws without a clear than
+// MaxIdleDraws allows. It returns false if maxNodes states are expanded without finding a
+// clear, or if the search space runs out.
+func (s *PuzzleSolver) FindAnySolution(maxNodes int) ([]game.Move, bool) {
+	visited := newStateTable(s.MaxTableEntries)
+	idleDrawLimit := s.idleDrawLimit()
+	order := s.moveOrder()
+	path := []game.Move{}
+	expanded := 0
+
+	var search func(g *game.PuzzleGame, idleDraws int) bool
+	search = func(g *game.PuzzleGame, idleDraws int) bool {
+		if g.IsSolved() {
+			return true
+		}
+		if expanded >= maxNodes || idleDraws > idleDrawLimit {
+			return false
+		}
+		hash := g.Hash()
+		if visited.contains(hash) {
+			return false
+		}
+		visited.add(hash)
+		expanded++
+
+		for _, move := range order(g, g.LegalMoves()) {
+			child := g.DeepCopy()
+			cleared := child.MakeMove(move.Source, move.Destination)
+			path = append(path, move)
+			childIdle := 0
+			if !cleared && move.Source == "DRAW" {
+				childIdle = idleDraws + 1
+			} else if !cleared {
+				childIdle = idleDraws
+			}
+			if search(child, childIdle) {
+				return true
+			}
+			path = path[:len(path)-1]
+		}
+		return false
+	}
+
+	if !search(s.originalGame.DeepCopy(), 0) {
+		return nil, false
+	}
+	return canonicalMoves(path), true
+}
+
+// MoveOrderFunc returns moves, the legal moves in g, in the order a search should try them.
+// It may drop moves to prune them. It must not modify g.
+type MoveOrderFunc func(g *game.PuzzleGame, moves []game.Move) []game.Move
+
+// DefaultMoveOrder tries smashes first, then the other clearing moves, those leaving the most
+// clearing moves available (MobilityScore) first, then non-clearing moves such as HOLD parks,
+// and DRAW last. Moves that rank equally keep their order.
+func DefaultMoveOrder(g *game.PuzzleGame, moves []game.Move) []game.Move {
+	type rankedMove struct {
+		move     game.Move
+		class    int // 0 smash, 1 other clear, 2 non-clearing, 3 draw
+		mobility int
+	}
+	ranked := make([]rankedMove, len(moves))
+	child := g.DeepCopy()
+	for i, move := range moves {
+		child.Reset(g)
+		cleared := child.MakeMove(move.Source, move.Destination)
+		ranked[i] = rankedMove{move: move, class: 2, mobility: child.MobilityScore()}
+		switch {
+		case move.Destination == "SMASH":
+			ranked[i].class = 0
+		case cleared:
+			ranked[i].class = 1
+		case move.Source == "DRAW":
+			ranked[i].class = 3
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].class != ranked[j].class {
+			return ranked[i].class < ranked[j].class
+		}
+		return ranked[i].class == 1 && ranked[i].mobility > ranked[j].mobility
+	})
+	ordered := make([]game.Move, len(ranked))
+	for i, r := range ranked {
+		ordered[i] = r.move
+	}
+	return ordered
+}
+
+// moveOrder resolves MoveOrder, defaulting to DefaultMoveOrder.
+func (s *PuzzleSolver) moveOrder() MoveOrderFunc {
+	if s.MoveOrder != nil {
+		return s.MoveOrder
+	}
+	return DefaultMoveOrder
+}
@@ -0,0 +1,64 @@
+package solver
+
+import (
+	"testing"
+
+	"pyramid_solver_go_local/game"
+)
+
+// drawMatchPuzzle sets up a pyramid of 13s except for a 1 at A1 and a 3 at A2, whose partners
+// are in the draw pile, so a clear needs matches against DRW1.
+func drawMatchPuzzle(t testing.TB) *game.PuzzleGame {
+	t.Helper()
+	stones := []int{1, 3}
+	for len(stones) < game.TotalPyramidStones {
+		stones = append(stones, 13)
+	}
+	g := game.NewPuzzleGame()
+	if err := g.SetupCustomGame(stones, []int{7, 8, 5, 2, 6, 4}); err != nil {
+		t.Fatalf("SetupCustomGame: %v", err)
+	}
+	return g
+}
+
+func TestFindAnySolution(t *testing.T) {
+	g := drawMatchPuzzle(t)
+	moves, ok := quietSolver(g).FindAnySolution(10_000)
+	if !ok {
+		t.Fatal("FindAnySolution found no clear of a solvable puzzle")
+	}
+	final, err := Replay(g, moves)
+	if err != nil {
+		t.Fatalf("solution %s does not replay: %v", game.EncodeMoves(moves), err)
+	}
+	if !final.IsSolved() {
+		t.Errorf("solution %s leaves the pyramid uncleared", game.EncodeMoves(moves))
+	}
+
+	if moves, ok := quietSolver(uniformPuzzle(t, 7, []int{1, 2, 3})).FindAnySolution(10_000); ok {
+		t.Errorf("FindAnySolution cleared a pyramid of 7s with %s", game.EncodeMoves(moves))
+	}
+}
+
+func TestMoveOrderIsUsed(t *testing.T) {
+	g := drawMatchPuzzle(t)
+	s := quietSolver(g)
+	calls := 0
+	s.MoveOrder = func(g *game.PuzzleGame, moves []game.Move) []game.Move {
+		calls++
+		return DefaultMoveOrder(g, moves)
+	}
+	if _, ok := s.FindAnySolution(10_000); !ok {
+		t.Fatal("FindAnySolution with a counting MoveOrder found no clear")
+	}
+	if calls == 0 {
+		t.Error("FindAnySolution never called the custom MoveOrder")
+	}
+
+	calls = 0
+	s.NodeBudget = 1000 // Enough to see the order used; the search need not finish
+	s.SolveAStar()
+	if calls == 0 {
+		t.Error("SolveAStar never called the custom MoveOrder")
+	}
+}
@@ -0,0 +1,118 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pyramid_solver_go_local/game"
+)
+
+// DiffSolutions returns the index of the first move at which a and b differ, or -1 if they agree
+// up to the length of the shorter one. Moves are compared canonicalized, so A1-A5 and A5-A1 count
+// as the same match.
+func DiffSolutions(a, b []game.Move) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if game.CanonicalizeMove(a[i]) != game.CanonicalizeMove(b[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// FormatDiff lays a and b out side by side, one move per line, with a marker on the line where
+// they first diverge. When they agree over the shorter length the marker is placed where the
+// shorter one ends, if the lengths differ.
+func FormatDiff(a, b []game.Move) string {
+	divergence := DiffSolutions(a, b)
+	if divergence == -1 && len(a) != len(b) {
+		divergence = len(a)
+		if len(b) < divergence {
+			divergence = len(b)
+		}
+	}
+
+	rows := len(a)
+	if len(b) > rows {
+		rows = len(b)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("      #  A              B\n")
+	for i := 0; i < rows; i++ {
+		marker := "  "
+		if i == divergence {
+			marker = ">>"
+		}
+		line := fmt.Sprintf("%s %4d  %-14s %s", marker, i+1, diffCell(a, i), diffCell(b, i))
+		sb.WriteString(strings.TrimRight(line, " "))
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// diffCell formats moves[i] for FormatDiff, or returns an empty cell past the end.
+func diffCell(moves []game.Move, i int) string {
+	if i >= len(moves) {
+		return ""
+	}
+	return game.EncodeMoves([]game.Move{moves[i]})
+}
+
+// CompareToOptimal scores playerMoves on g with Replay, runs a Monte Carlo solve of iterations
+// simulations on g, and finds the first player move that gave something up: the earliest index
+// i at which the best score the solver finds from the position after the player's first i moves
+// is higher than from the position after i+1. The best score from a position is at least the
+// best from any later position along the player's path, so the estimates are made never to
+// increase along it, which keeps Monte Carlo noise from flagging a good move. firstSuboptimalIndex
+// is -1 if no move gave anything up. If a player move is illegal, playerScore is -1 and
+// firstSuboptimalIndex is that move's index. A player's moves cannot be replayed on a draw pile
+// with unknown stones, so if g has any all three results are -1.
+//
+// One solve is run from every position along the player's path, so this costs
+// (len(playerMoves)+1)*iterations simulations.
+func CompareToOptimal(g *game.PuzzleGame, playerMoves []game.Move, iterations int) (playerScore, solverScore int, firstSuboptimalIndex int) {
+	if checkKnownDrawPile(g) != nil {
+		return -1, -1, -1
+	}
+	s := NewPuzzleSolver(g)
+	s.Verbosity = Quiet
+	_, solverScore = s.SolveMonteCarlo(iterations)
+
+	final, err := Replay(g, playerMoves)
+	if err != nil {
+		var illegal *IllegalMoveError
+		if errors.As(err, &illegal) {
+			return -1, solverScore, illegal.Index
+		}
+		return -1, solverScore, 0
+	}
+	playerScore = final.CalculateScore()
+
+	// reachable[i] is the best score found from the position after the player's first i moves
+	reachable := make([]int, len(playerMoves)+1)
+	for i := len(playerMoves); i >= 0; i-- {
+		found := solverScore
+		if i > 0 {
+			result, _ := s.solveMonteCarlo(context.Background(), iterations, solveOptions{objective: MaxScore, prefix: playerMoves[:i]})
+			found = result.Score
+		}
+		later := playerScore
+		if i < len(playerMoves) {
+			later = reachable[i+1]
+		}
+		reachable[i] = max(found, later)
+	}
+
+	for i := 0; i < len(playerMoves); i++ {
+		if reachable[i] > reachable[i+1] {
+			return playerScore, solverScore, i
+		}
+	}
+	return playerScore, solverScore, -1
+}
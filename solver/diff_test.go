@@ -0,0 +1,65 @@
+package solver
+
+import (
+	"strings"
+	"testing"
+
+	"pyramid_solver_go_local/game"
+)
+
+func TestDiffSolutions(t *testing.T) {
+	decode := func(s string) []game.Move {
+		t.Helper()
+		moves, err := game.DecodeMoves(s)
+		if err != nil {
+			t.Fatalf("DecodeMoves(%q): %v", s, err)
+		}
+		return moves
+	}
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"A1-A5 A3-A7 DRAW", "A1-A5 A3-A7 A2-SMASH", 2},
+		{"A1-A5 DRAW", "A5-A1 DRAW", -1}, // The same match written the other way round
+		{"A1-A5", "A1-A5 DRAW", -1},
+		{"DRAW A1-A5", "A1-A5 DRAW", 0},
+	}
+	for _, tt := range tests {
+		if got := DiffSolutions(decode(tt.a), decode(tt.b)); got != tt.want {
+			t.Errorf("DiffSolutions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	diff := FormatDiff(decode("A1-A5 A3-A7 DRAW"), decode("A1-A5 A3-A7 A2-SMASH"))
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("FormatDiff has %d lines, want a header and 3 moves:\n%s", len(lines), diff)
+	}
+	for i, line := range lines[1:] {
+		if marked := strings.HasPrefix(line, ">>"); marked != (i == 2) {
+			t.Errorf("line for move %d marked %v, want only move 2 marked:\n%s", i, marked, diff)
+		}
+	}
+}
+
+func TestCompareToOptimal(t *testing.T) {
+	g := examplePuzzle(t)
+	_, _, worst, worstScore := quietSolver(g).SolveMonteCarloWithWorst(100)
+
+	playerScore, solverScore, index := CompareToOptimal(g, worst, 100)
+	if playerScore != worstScore {
+		t.Errorf("player score = %d, want the worst rollout's %d", playerScore, worstScore)
+	}
+	if solverScore <= playerScore {
+		t.Fatalf("solver score %d is not above the worst rollout's %d", solverScore, playerScore)
+	}
+	if index < 0 || index >= len(worst) {
+		t.Errorf("first suboptimal index = %d, want one of the %d moves played", index, len(worst))
+	}
+
+	illegal := []game.Move{{Source: "A1", Destination: "A5"}, {Source: "G1", Destination: "SMASH"}}
+	if playerScore, _, index := CompareToOptimal(g, illegal, 50); playerScore != -1 || index != 1 {
+		t.Errorf("CompareToOptimal of an illegal second move = (%d, _, %d), want (-1, _, 1)", playerScore, index)
+	}
+}
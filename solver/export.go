@@ -0,0 +1,62 @@
+package solver
+
+import (
+	"encoding/json"
+
+	"pyramid_solver_go_local/game"
+)
+
+// ReplayExport is the JSON document written by ExportReplay. States[0] is the starting state
+// and States[i] the state after Moves[i-1], so a viewer can show any step without the engine.
+type ReplayExport struct {
+	Moves  []game.Move   `json:"moves"`
+	States []ReplayState `json:"states"`
+}
+
+// ReplayState is one snapshot in a ReplayExport.
+type ReplayState struct {
+	Grid     [][]int `json:"grid"` // Rows A to G, as returned by Grid; -1 is empty
+	Hold     int     `json:"hold"`
+	DrawTop  int     `json:"drawTop"` // Current draw stone, -1 if none
+	DrawPile [][]int `json:"drawPile"`
+	Score    int     `json:"score"`
+	Solved   bool    `json:"solved"`
+}
+
+// ExportReplay replays moves on a copy of g and returns a ReplayExport as JSON, with a
+// snapshot of the state before the first move and after every move. It returns an
+// *IllegalMoveError if a move is not legal when played, and an error wrapping
+// ErrUnknownDrawStones if g's draw pile has unknown stones.
+func ExportReplay(g *game.PuzzleGame, moves []game.Move) ([]byte, error) {
+	if err := checkKnownDrawPile(g); err != nil {
+		return nil, err
+	}
+	replayed := g.DeepCopy()
+	export := ReplayExport{
+		Moves:  copyMoves(moves),
+		States: []ReplayState{replayState(replayed)},
+	}
+	if export.Moves == nil {
+		export.Moves = []game.Move{}
+	}
+	for i, move := range moves {
+		if !replayed.IsLegalMove(move) {
+			return nil, &IllegalMoveError{Index: i, Move: move}
+		}
+		replayed.MakeMove(move.Source, move.Destination)
+		export.States = append(export.States, replayState(replayed))
+	}
+	return json.Marshal(export)
+}
+
+// replayState snapshots g for ExportReplay.
+func replayState(g *game.PuzzleGame) ReplayState {
+	return ReplayState{
+		Grid:     g.Grid(),
+		Hold:     g.HoldValue(),
+		DrawTop:  g.GetCurrentDrawStone(),
+		DrawPile: g.DrawPile(),
+		Score:    g.CalculateScore(),
+		Solved:   g.IsSolved(),
+	}
+}
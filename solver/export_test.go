@@ -0,0 +1,38 @@
+package solver
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"pyramid_solver_go_local/game"
+)
+
+func TestExportReplay(t *testing.T) {
+	g := examplePuzzle(t)
+	moves, score := quietSolver(g).SolveMonteCarlo(300)
+	data, err := ExportReplay(g, moves)
+	if err != nil {
+		t.Fatalf("ExportReplay: %v", err)
+	}
+
+	var export ReplayExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("export is not valid JSON: %v", err)
+	}
+	if len(export.States) != len(moves)+1 {
+		t.Fatalf("export has %d states for %d moves, want %d", len(export.States), len(moves), len(moves)+1)
+	}
+	if first := export.States[0]; first.Score != g.CalculateScore() || first.Grid[0][0] != g.PyramidValue(0, 0) {
+		t.Errorf("first state %+v is not the starting state", first)
+	}
+	if last := export.States[len(moves)]; last.Score != score {
+		t.Errorf("last state scores %d, want the solution's %d", last.Score, score)
+	}
+
+	illegal := []game.Move{{Source: "G1", Destination: "SMASH"}}
+	var illegalErr *IllegalMoveError
+	if _, err := ExportReplay(g, illegal); !errors.As(err, &illegalErr) || illegalErr.Index != 0 {
+		t.Errorf("ExportReplay of an illegal move returned %v, want an *IllegalMoveError at 0", err)
+	}
+}
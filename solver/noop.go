@@ -0,0 +1,66 @@
+package solver
+
+import (
+	"runtime"
+
+	"pyramid_solver_go_local/game"
+)
+
+// NoOpSolver exercises the same scaffolding as SolveMonteCarlo (worker goroutines, job and
+// result channels, DeepCopy and Reset) without choosing any moves. Timing it against a real
+// solve shows how much of the runtime is harness overhead rather than search.
+type NoOpSolver struct {
+	// ResetsPerSimulation is the number of Reset + CalculateScore calls per simulation.
+	// Values below 1 are treated as 1.
+	ResetsPerSimulation int
+}
+
+// Solve runs iterations empty simulations on g and returns no moves and g's current score.
+func (n NoOpSolver) Solve(g *game.PuzzleGame, iterations int) ([]game.Move, int) {
+	resets := n.ResetsPerSimulation
+	if resets < 1 {
+		resets = 1
+	}
+
+	numWorkers := runtime.NumCPU()
+	jobs := make(chan Job, numWorkers)
+	results := make(chan Result, numWorkers)
+
+	for w := 0; w < numWorkers; w++ {
+		go func() {
+			simulatedGame := g.DeepCopy()
+			for job := range jobs {
+				score := -1
+				for i := 0; i < job.NumSimulations; i++ {
+					for j := 0; j < resets; j++ {
+						simulatedGame.Reset(g)
+						score = simulatedGame.CalculateScore()
+					}
+				}
+				results <- Result{Score: score}
+			}
+		}()
+	}
+
+	simsPerWorker := iterations / numWorkers
+	sent := 0
+	for w := 0; w < numWorkers; w++ {
+		numSims := simsPerWorker
+		if w == numWorkers-1 {
+			numSims += iterations % numWorkers
+		}
+		if numSims > 0 {
+			jobs <- Job{NumSimulations: numSims}
+			sent++
+		}
+	}
+	close(jobs)
+
+	bestScore := -1
+	for i := 0; i < sent; i++ {
+		if result := <-results; result.Score > bestScore {
+			bestScore = result.Score
+		}
+	}
+	return nil, bestScore
+}
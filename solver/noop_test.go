@@ -0,0 +1,23 @@
+package solver
+
+import "testing"
+
+func TestNoOpSolverReturnsCurrentScore(t *testing.T) {
+	g := examplePuzzle(t)
+	moves, score := NoOpSolver{}.Solve(g, 100)
+	if moves != nil {
+		t.Errorf("NoOpSolver returned moves %v, want none", moves)
+	}
+	if want := g.CalculateScore(); score != want {
+		t.Errorf("NoOpSolver score = %d, want the game's current score %d", score, want)
+	}
+}
+
+func BenchmarkNoOpSolver(b *testing.B) {
+	g := examplePuzzle(b)
+	const simsPerOp = 1000
+	for i := 0; i < b.N; i++ {
+		NoOpSolver{}.Solve(g, simsPerOp)
+	}
+	b.ReportMetric(float64(b.N*simsPerOp)/b.Elapsed().Seconds(), "sims/s")
+}
@@ -0,0 +1,139 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+
+	"pyramid_solver_go_local/game"
+	"pyramid_solver_go_local/utils"
+)
+
+// IllegalMoveError reports the first move of a sequence that was not legal when it was played.
+type IllegalMoveError struct {
+	Index int // 0-based position of the move in the sequence
+	Move  game.Move
+}
+
+func (e *IllegalMoveError) Error() string {
+	return fmt.Sprintf("move %d (%s -> %s) is not legal", e.Index, e.Move.Source, e.Move.Destination)
+}
+
+// ErrUnknownDrawStones reports a replay on a game whose draw pile has unknown stones. The
+// solver plays such games on a sampled pile; replay its moves on the solver's LastStart.
+var ErrUnknownDrawStones = errors.New("draw pile has unknown stones")
+
+// checkKnownDrawPile returns an error wrapping ErrUnknownDrawStones if g's draw pile has
+// unknown stones.
+func checkKnownDrawPile(g *game.PuzzleGame) error {
+	if unknown := g.UnknownDrawStones(); unknown > 0 {
+		return fmt.Errorf("%w: %d of them; replay on the sampled pile of the solver's LastStart", ErrUnknownDrawStones, unknown)
+	}
+	return nil
+}
+
+// Replay plays moves on a copy of g, checking each against the legal moves at that point.
+// It returns the resulting game, or an *IllegalMoveError for the first illegal move.
+// Pyramid-to-pyramid matches are accepted in either order. g is not modified. Replaying on a
+// draw pile with unknown stones is an error wrapping ErrUnknownDrawStones.
+func Replay(g *game.PuzzleGame, moves []game.Move) (*game.PuzzleGame, error) {
+	if err := checkKnownDrawPile(g); err != nil {
+		return nil, err
+	}
+	replayed := g.DeepCopy()
+	for i, move := range moves {
+		if !replayed.IsLegalMove(move) {
+			return nil, &IllegalMoveError{Index: i, Move: move}
+		}
+		replayed.MakeMove(move.Source, move.Destination)
+	}
+	return replayed, nil
+}
+
+// MinimizeMoves shortens a solution by greedily dropping moves whose removal keeps the sequence
+// legal without clearing fewer pyramid stones or lowering the score. Draws and HOLD parks that
+// never paid off are the usual casualties. It returns the shortened sequence and its score, or
+// the original moves and -1 if they do not replay legally on g.
+func MinimizeMoves(g *game.PuzzleGame, moves []game.Move) ([]game.Move, int) {
+	best := copyMoves(moves)
+	final, err := Replay(g, best)
+	if err != nil {
+		return best, -1
+	}
+	bestCleared := clearedCount(final)
+	bestScore := final.CalculateScore()
+
+	for improved := true; improved; {
+		improved = false
+		// Walk backwards so dropping a move never shifts the indices still to be tried
+		for i := len(best) - 1; i >= 0; i-- {
+			candidate := make([]game.Move, 0, len(best)-1)
+			candidate = append(candidate, best[:i]...)
+			candidate = append(candidate, best[i+1:]...)
+
+			replayed, err := Replay(g, candidate)
+			if err != nil {
+				continue
+			}
+			cleared, score := clearedCount(replayed), replayed.CalculateScore()
+			if cleared >= bestCleared && score >= bestScore {
+				best, bestCleared, bestScore = candidate, cleared, score
+				improved = true
+			}
+		}
+	}
+	return best, bestScore
+}
+
+// MaximizeStreak reorders a solution to raise the streak bonus, by pulling moves earlier past
+// the draws and parks that break up runs of matches. A move is only moved if the sequence still
+// replays legally, clears as many pyramid stones, and scores strictly higher, so moves that
+// depend on an earlier clear stay behind it. It returns the reordered sequence and its score, or
+// the original moves and -1 if they do not replay legally on g.
+func MaximizeStreak(g *game.PuzzleGame, moves []game.Move) ([]game.Move, int) {
+	best := copyMoves(moves)
+	final, err := Replay(g, best)
+	if err != nil {
+		return best, -1
+	}
+	bestCleared := clearedCount(final)
+	bestScore := final.CalculateScore()
+
+	for improved := true; improved; {
+		improved = false
+		for i := 1; i < len(best); i++ {
+			for j := 0; j < i; j++ {
+				// Move best[i] to index j, shifting best[j:i] one later
+				candidate := make([]game.Move, 0, len(best))
+				candidate = append(candidate, best[:j]...)
+				candidate = append(candidate, best[i])
+				candidate = append(candidate, best[j:i]...)
+				candidate = append(candidate, best[i+1:]...)
+
+				replayed, err := Replay(g, candidate)
+				if err != nil {
+					continue
+				}
+				cleared, score := clearedCount(replayed), replayed.CalculateScore()
+				if cleared >= bestCleared && score > bestScore {
+					best, bestCleared, bestScore = candidate, cleared, score
+					improved = true
+					break
+				}
+			}
+		}
+	}
+	return best, bestScore
+}
+
+// clearedCount returns how many pyramid positions are empty in g.
+func clearedCount(g *game.PuzzleGame) int {
+	cleared := 0
+	for rowIdx, rowSize := range utils.PyramidRowSizes {
+		for colIdx := 0; colIdx < rowSize; colIdx++ {
+			if g.PyramidValue(rowIdx, colIdx) == -1 {
+				cleared++
+			}
+		}
+	}
+	return cleared
+}
@@ -0,0 +1,76 @@
+package solver
+
+import (
+	"testing"
+
+	"pyramid_solver_go_local/game"
+)
+
+// smashAllMoves returns the moves that clear a pyramid of 13s by smashing, base row first.
+func smashAllMoves(g *game.PuzzleGame) []game.Move {
+	board := g.DeepCopy()
+	moves := []game.Move{}
+	for !board.IsSolved() {
+		move := game.Move{Source: board.GetAccessiblePositions()[0], Destination: "SMASH"}
+		board.MakeMove(move.Source, move.Destination)
+		moves = append(moves, move)
+	}
+	return moves
+}
+
+func TestMinimizeMovesDropsWastedDraws(t *testing.T) {
+	g := uniformPuzzle(t, 13, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	draw := game.Move{Source: "DRAW", Destination: "DRAW"}
+	smashes := smashAllMoves(g)
+	padded := append([]game.Move{draw, draw}, smashes[:10]...)
+	padded = append(padded, draw)
+	padded = append(padded, smashes[10:]...)
+
+	before, err := Replay(g, padded)
+	if err != nil {
+		t.Fatalf("padded solution does not replay: %v", err)
+	}
+	minimized, score := MinimizeMoves(g, padded)
+	if len(minimized) != len(smashes) {
+		t.Errorf("minimized to %d moves (%s), want the %d smashes", len(minimized), game.EncodeMoves(minimized), len(smashes))
+	}
+	if score < before.CalculateScore() {
+		t.Errorf("minimized score %d is below the padded score %d", score, before.CalculateScore())
+	}
+	if after, err := Replay(g, minimized); err != nil || !after.IsSolved() {
+		t.Errorf("minimized solution no longer clears the pyramid (err %v)", err)
+	}
+}
+
+func TestMaximizeStreakKeepsMatchesTogether(t *testing.T) {
+	g := uniformPuzzle(t, 13, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	draw := game.Move{Source: "DRAW", Destination: "DRAW"}
+	smashes := smashAllMoves(g)
+	// A draw every few smashes breaks the streak each time
+	broken := []game.Move{}
+	for i, move := range smashes {
+		if i > 0 && i%7 == 0 {
+			broken = append(broken, draw)
+		}
+		broken = append(broken, move)
+	}
+	before, err := Replay(g, broken)
+	if err != nil {
+		t.Fatalf("broken-up solution does not replay: %v", err)
+	}
+
+	reordered, score := MaximizeStreak(g, broken)
+	after, err := Replay(g, reordered)
+	if err != nil {
+		t.Fatalf("reordered solution does not replay: %v", err)
+	}
+	if after.CalculateScore() != score || !after.IsSolved() {
+		t.Errorf("reordered solution replays to %d (solved %v), reported %d", after.CalculateScore(), after.IsSolved(), score)
+	}
+	if got, was := after.ScoreBreakdown().Streak, before.ScoreBreakdown().Streak; got <= was {
+		t.Errorf("streak bonus %d after reordering, want above the original %d", got, was)
+	}
+	if _, score := MaximizeStreak(g, []game.Move{{Source: "G1", Destination: "SMASH"}}); score != -1 {
+		t.Errorf("MaximizeStreak of an illegal solution scored %d, want -1", score)
+	}
+}
@@ -0,0 +1,76 @@
+package solver
+
+import (
+	"fmt"
+
+	"pyramid_solver_go_local/game"
+)
+
+// Replayer steps back and forth through a solution, for interactive solution viewers.
+// Position i means the first i moves have been played.
+type Replayer struct {
+	initial *game.PuzzleGame
+	moves   []game.Move
+	index   int
+	current *game.PuzzleGame
+}
+
+// NewReplayer checks that moves replay legally on initial and returns a Replayer positioned
+// before the first move. initial is copied and never modified.
+func NewReplayer(initial *game.PuzzleGame, moves []game.Move) (*Replayer, error) {
+	if _, err := Replay(initial, moves); err != nil {
+		return nil, err
+	}
+	return &Replayer{
+		initial: initial.DeepCopy(),
+		moves:   copyMoves(moves),
+		current: initial.DeepCopy(),
+	}, nil
+}
+
+// Index returns the number of moves played so far.
+func (r *Replayer) Index() int {
+	return r.index
+}
+
+// Len returns the number of moves in the solution.
+func (r *Replayer) Len() int {
+	return len(r.moves)
+}
+
+// StepForward plays the next move.
+func (r *Replayer) StepForward() error {
+	if r.index >= len(r.moves) {
+		return fmt.Errorf("already at the end of the solution (%d moves)", len(r.moves))
+	}
+	move := r.moves[r.index]
+	r.current.MakeMove(move.Source, move.Destination)
+	r.index++
+	return nil
+}
+
+// StepBack undoes the last move played.
+func (r *Replayer) StepBack() error {
+	if r.index == 0 {
+		return fmt.Errorf("already at the start of the solution")
+	}
+	return r.Seek(r.index - 1)
+}
+
+// Seek jumps to the state after the first index moves, replaying from the start.
+func (r *Replayer) Seek(index int) error {
+	if index < 0 || index > len(r.moves) {
+		return fmt.Errorf("index %d out of range [0, %d]", index, len(r.moves))
+	}
+	r.current.Reset(r.initial)
+	for _, move := range r.moves[:index] {
+		r.current.MakeMove(move.Source, move.Destination)
+	}
+	r.index = index
+	return nil
+}
+
+// State returns a copy of the game at the current position.
+func (r *Replayer) State() *game.PuzzleGame {
+	return r.current.DeepCopy()
+}
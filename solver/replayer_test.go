@@ -0,0 +1,60 @@
+package solver
+
+import (
+	"testing"
+
+	"pyramid_solver_go_local/game"
+)
+
+func TestReplayerMatchesFreshReplay(t *testing.T) {
+	g := examplePuzzle(t)
+	moves, _ := quietSolver(g).SolveMonteCarlo(300)
+	r, err := NewReplayer(g, moves)
+	if err != nil {
+		t.Fatalf("NewReplayer: %v", err)
+	}
+	if r.Len() != len(moves) {
+		t.Fatalf("Len() = %d, want %d", r.Len(), len(moves))
+	}
+
+	// checkAt compares the replayer's state with a fresh replay of the first index moves
+	checkAt := func(step string, index int) {
+		t.Helper()
+		want, err := Replay(g, moves[:index])
+		if err != nil {
+			t.Fatalf("Replay of %d moves: %v", index, err)
+		}
+		if r.Index() != index {
+			t.Fatalf("after %s Index() = %d, want %d", step, r.Index(), index)
+		}
+		if got := r.State(); got.Hash() != want.Hash() {
+			t.Errorf("after %s the state at %d differs from a fresh replay", step, index)
+		}
+	}
+	for _, index := range []int{len(moves), 0, len(moves) / 2, 1, len(moves) - 1} {
+		if err := r.Seek(index); err != nil {
+			t.Fatalf("Seek(%d): %v", index, err)
+		}
+		checkAt("Seek", index)
+	}
+	if err := r.StepForward(); err != nil {
+		t.Fatalf("StepForward: %v", err)
+	}
+	checkAt("StepForward", len(moves))
+	if err := r.StepForward(); err == nil {
+		t.Error("StepForward past the end did not fail")
+	}
+	if err := r.StepBack(); err != nil {
+		t.Fatalf("StepBack: %v", err)
+	}
+	checkAt("StepBack", len(moves)-1)
+	if err := r.Seek(len(moves) + 1); err == nil {
+		t.Error("Seek past the end did not fail")
+	}
+}
+
+func TestNewReplayerRejectsIllegalMoves(t *testing.T) {
+	if _, err := NewReplayer(examplePuzzle(t), []game.Move{{Source: "G1", Destination: "SMASH"}}); err == nil {
+		t.Error("NewReplayer accepted a move on a buried stone")
+	}
+}
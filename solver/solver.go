@@ -1,195 +1,1061 @@
 package solver
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"math/rand"
+	"os"
 	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"pyramid_solver_go_local/game"
-	"pyramid_solver_go_local/utils"
 )
 
-// PuzzleSolver manages the Monte Carlo simulation.
+// Solver is implemented by anything that can search a puzzle for its best move sequence.
+type Solver interface {
+	Solve(g *game.PuzzleGame, iterations int) ([]game.Move, int)
+}
+
+// MonteCarloSolver adapts PuzzleSolver to the Solver interface, building a fresh
+// PuzzleSolver for every puzzle it is given.
+type MonteCarloSolver struct{}
+
+// Solve runs SolveMonteCarlo on g.
+func (MonteCarloSolver) Solve(g *game.PuzzleGame, iterations int) ([]game.Move, int) {
+	return NewPuzzleSolver(g).SolveMonteCarlo(iterations)
+}
+
+// PuzzleSolver manages the Monte Carlo simulation. A solve keeps its results in local state,
+// so one PuzzleSolver may run several solves concurrently as long as its option fields are
+// not changed meanwhile.
+//
+// Draw stones set up as unknown (utils.UnknownStone) are sampled afresh for every rollout with
+// SampleUnknownDrawStones. The best moves and score are then those of the best rollout under
+// its own sampling, a plan to follow until the real stones are revealed.
 type PuzzleSolver struct {
 	originalGame *game.PuzzleGame
-	bestScore    int
-	bestMoves    []game.Move
+
+	// Objective decides which rollout counts as best.
+	Objective Objective
+
+	// Weights, if set, re-weights the score's components when ranking rollouts, for example
+	// to stop the time bonus favouring fast partial clears. Reported scores are unaffected.
+	Weights *ObjectiveWeights
+
+	// SmashGatedThirteens makes rollouts smash accessible 13s whenever smashing is the only
+	// way left to clear anything, instead of drawing or parking. It is a playout heuristic
+	// for boards where a 13 gates progress; the rules are unchanged.
+	SmashGatedThirteens bool
+
+	// AvoidRepeats makes each rollout remember the board positions it has passed through and
+	// skip non-clearing moves that return to one, such as useless HOLD shuffles, unless no
+	// other move is left. The memory is capped at maxRecentStates positions per rollout.
+	AvoidRepeats bool
+
+	// AvoidStranding makes rollouts skip moves that use up the last partner of a value still in
+	// the pyramid, leaving more stones impossible to clear than HOLD can take, unless no other
+	// move is left.
+	AvoidStranding bool
+
+	// PruneHoldParks drops moves parking a pyramid stone in the empty HOLD when its partner is
+	// hard to reach, by game.HoldValueEstimate below minHoldParkValue, so rollouts spend fewer
+	// moves on parks that rarely help. Parks of the DRW1 stone are kept.
+	PruneHoldParks bool
+
+	// Selection decides how a rollout picks its next move.
+	Selection Selection
+
+	// Temperature controls Softmax selection: low values almost always take the move with the
+	// best one-ply score, high values approach a uniform pick. Zero means defaultTemperature.
+	Temperature float64
+
+	// TargetScore ends the search early once any worker reaches it. It defaults to the
+	// puzzle's MaxPossibleScore; lower it to accept a known near-max result. Zero disables it.
+	TargetScore int
+
+	// NodeBudget caps how many states SolveAStar expands.
+	NodeBudget int
+
+	// MaxTableEntries caps how many visited states SolveAStar and FindAnySolution remember, to
+	// bound their memory on hard puzzles. Once the table is full a random state is forgotten
+	// for each new one, and may be explored again. Zero means no cap.
+	MaxTableEntries int
+
+	// MoveOrder orders the candidate moves SolveAStar and FindAnySolution expand from each
+	// state. Nil means DefaultMoveOrder.
+	MoveOrder MoveOrderFunc
+
+	// MaxIdleDraws aborts a rollout after this many consecutive draws without a clear, which
+	// stops rollouts spiralling through -50 redraws on a stuck board. Zero means one draw per
+	// stone in the starting draw pile.
+	MaxIdleDraws int
+
+	// MaxMoves caps the length of a single rollout. Rollouts that reach it without clearing
+	// the pyramid are cut off and counted in Stats.Truncated.
+	MaxMoves int
+
+	// SeedMoves, if set, is a previous best solution for the puzzle to build on. The solve
+	// never returns anything worse than it, and resumeFraction of the rollouts replay a random
+	// prefix of it before exploring on their own. A seed that does not replay legally is
+	// ignored. A seed playing a move the solve rules out, such as a forbidden move in
+	// SolveConstrained, is not returned, and rollouts only follow it up to that move. When the
+	// draw pile has unknown stones the seed cannot be scored, so it is not returned either,
+	// and rollouts follow it as far as their sampled pile allows.
+	SeedMoves []game.Move
+
+	// Progress, if set, is called about every progressInterval during a Monte Carlo solve with
+	// the simulations finished so far, the total requested and the time since the solve began.
+	// It runs on its own goroutine, never concurrently with itself.
+	Progress func(simsRun, simsTotal int, elapsed time.Duration)
+
+	// Verbosity selects how much a Monte Carlo solve prints to Output, which is os.Stdout when
+	// nil. NewPuzzleSolver sets Normal.
+	Verbosity Verbosity
+	Output    io.Writer
+
+	statsMu   sync.Mutex
+	stats     Stats            // Guarded by statsMu
+	lastStart *game.PuzzleGame // Guarded by statsMu; nil when the best result played originalGame
+	outputMu  sync.Mutex
+}
+
+// Verbosity is a PuzzleSolver output level.
+type Verbosity int
+
+const (
+	// Quiet prints nothing.
+	Quiet Verbosity = iota
+	// Normal reports the start and end of each solve and anything that went wrong.
+	Normal
+	// Debug also reports each job a worker takes and the best score every progressInterval.
+	Debug
+)
+
+// defaultMaxMoves is the rollout length cap used unless MaxMoves is changed.
+const defaultMaxMoves = 200
+
+// defaultTemperature is the Softmax temperature used unless Temperature is set. It is on the
+// scale of a single match (50 points plus streak bonus).
+const defaultTemperature = 100.0
+
+// maxRecentStates bounds the positions an AvoidRepeats rollout remembers; the memory is
+// cleared when it fills up.
+const maxRecentStates = 512
+
+// minHoldParkValue is the lowest HoldValueEstimate a park survives PruneHoldParks with: the
+// partner is at most three draws or blocking stones away.
+const minHoldParkValue = 0.25
+
+// resumeFraction is the share of rollouts that start from a prefix of SeedMoves.
+const resumeFraction = 0.5
+
+// progressInterval is how often a Monte Carlo solve reports to Progress.
+const progressInterval = 500 * time.Millisecond
+
+// defaultNodeBudget keeps a default SolveAStar run to a few hundred megabytes at most.
+const defaultNodeBudget = 20000
+
+// Objective selects what the solver optimizes when ranking rollouts.
+type Objective int
+
+const (
+	// MaxScore keeps the highest-scoring rollout.
+	MaxScore Objective = iota
+	// MaxClear keeps the rollout that clears the most pyramid stones, using score only to
+	// break ties, so a full clear beats a higher-scoring partial one.
+	MaxClear
+	// ShortestClear keeps the rollout that clears the whole pyramid in the fewest moves, using
+	// score to break ties. Until some rollout clears fully it behaves like MaxClear.
+	ShortestClear
+)
+
+// ObjectiveWeights scales each component of a game.ScoreBreakdown when ranking rollouts.
+// A weight of 1 counts the component as scored, 0 ignores it.
+type ObjectiveWeights struct {
+	Matches    float64
+	Leftover   float64
+	Redraws    float64
+	Streak     float64
+	Completion float64
+	TimeBonus  float64
+}
+
+// DefaultObjectiveWeights ranks rollouts exactly by score; copy it and change single weights.
+var DefaultObjectiveWeights = ObjectiveWeights{Matches: 1, Leftover: 1, Redraws: 1, Streak: 1, Completion: 1, TimeBonus: 1}
+
+// apply returns the weighted sum of b's components, rounded to the nearest point.
+func (w ObjectiveWeights) apply(b game.ScoreBreakdown) int {
+	weighted := w.Matches*float64(b.Matches) + w.Leftover*float64(b.Leftover) + w.Redraws*float64(b.Redraws) +
+		w.Streak*float64(b.Streak) + w.Completion*float64(b.Completion) + w.TimeBonus*float64(b.TimeBonus)
+	return int(math.Round(weighted))
+}
+
+// Selection chooses the rollout policy used by the Monte Carlo workers.
+type Selection int
+
+const (
+	// BiasedRandom takes a random clearing move 80% of the time, otherwise any random move.
+	BiasedRandom Selection = iota
+	// Softmax samples each move with probability proportional to exp(score/Temperature),
+	// where score is the game's score after playing it.
+	Softmax
+)
+
+// Stats describes the work done by the most recent solve.
+type Stats struct {
+	SimulationsRequested int
+	SimulationsRun       int     // Fewer than requested when the solve stopped early
+	Truncated            int     // Rollouts cut off by MaxMoves rather than finishing or getting stuck
+	Solved               bool    // Whether the best result cleared the pyramid
+	MeanScore            float64 // Mean final score of the rollouts run
 }
 
 // NewPuzzleSolver creates a new PuzzleSolver.
 func NewPuzzleSolver(originalGame *game.PuzzleGame) *PuzzleSolver {
 	return &PuzzleSolver{
 		originalGame: originalGame,
-		bestScore:    -1,
-		bestMoves:    []game.Move{},
+		TargetScore:  originalGame.MaxPossibleScore(),
+		NodeBudget:   defaultNodeBudget,
+		MaxMoves:     defaultMaxMoves,
+		Verbosity:    Normal,
 	}
 }
 
+// logf prints a message to Output if Verbosity is at least level. Workers call it
+// concurrently.
+func (s *PuzzleSolver) logf(level Verbosity, format string, args ...any) {
+	if s.Verbosity < level {
+		return
+	}
+	out := s.Output
+	if out == nil {
+		out = os.Stdout
+	}
+	s.outputMu.Lock()
+	defer s.outputMu.Unlock()
+	fmt.Fprintf(out, format, args...)
+}
+
+// LastStats returns the statistics of the most recent solve.
+func (s *PuzzleSolver) LastStats() Stats {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	return s.stats
+}
+
+// LastStart returns a copy of the game the best moves of the most recent Monte Carlo solve
+// were played from. It is the solver's game, except that when its draw pile has unknown
+// stones they are the values sampled for the best rollout, so the moves replay on it.
+func (s *PuzzleSolver) LastStart() *game.PuzzleGame {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	if s.lastStart == nil {
+		return s.originalGame.DeepCopy()
+	}
+	return s.lastStart.DeepCopy()
+}
+
+// setLastRun records the statistics and best result of a solve for LastStats and LastStart.
+func (s *PuzzleSolver) setLastRun(stats Stats, best Result) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	s.stats = stats
+	s.lastStart = best.Start
+}
+
 // --- Structs for Parallel Processing ---
 type Job struct {
 	NumSimulations int
 	Seed           int64
 }
 type Result struct {
-	Score int
-	Moves []game.Move
+	Score   int
+	Moves   []game.Move
+	Cleared int  // Pyramid stones cleared by Moves
+	Ranking int  // Score under the solver's Weights; the score itself when Weights is nil
+	Solved  bool // Whether Moves clear the pyramid
+
+	// Start is the game Moves were played from when the solver's draw pile has unknown
+	// stones: a copy with them as sampled for this rollout. It is nil otherwise.
+	Start *game.PuzzleGame
+}
+
+// canonicalMoves returns a copy of moves with every move canonicalized.
+func canonicalMoves(moves []game.Move) []game.Move {
+	canonical := make([]game.Move, len(moves))
+	for i, move := range moves {
+		canonical[i] = game.CanonicalizeMove(move)
+	}
+	return canonical
+}
+
+// better reports whether result a beats b under objective o.
+func (o Objective) better(a, b Result) bool {
+	switch o {
+	case MaxClear:
+		if a.Cleared != b.Cleared {
+			return a.Cleared > b.Cleared
+		}
+	case ShortestClear:
+		if a.Cleared != b.Cleared {
+			return a.Cleared > b.Cleared
+		}
+		if a.Cleared == game.TotalPyramidStones && len(a.Moves) != len(b.Moves) {
+			return len(a.Moves) < len(b.Moves)
+		}
+	}
+	return betterSolution(a.Ranking, a.Moves, b.Ranking, b.Moves)
+}
+
+// ranking returns the value rollouts ending in g are ranked by: score, re-weighted by Weights
+// when it is set.
+func (s *PuzzleSolver) ranking(g *game.PuzzleGame, score int) int {
+	if s.Weights == nil {
+		return score
+	}
+	return s.Weights.apply(g.ScoreBreakdown())
+}
+
+// betterSolution reports whether the solution (score, moves) beats (bestScore, bestMoves).
+// A higher score wins; ties go to fewer moves and then to the lexicographically smaller
+// EncodeMoves string, so the choice never depends on goroutine scheduling.
+func betterSolution(score int, moves []game.Move, bestScore int, bestMoves []game.Move) bool {
+	if score != bestScore {
+		return score > bestScore
+	}
+	if len(moves) != len(bestMoves) {
+		return len(moves) < len(bestMoves)
+	}
+	return game.EncodeMoves(moves) < game.EncodeMoves(bestMoves)
+}
+
+// sharedProgress is read and written by every worker of a single solve.
+type sharedProgress struct {
+	bestScore atomic.Int64 // Best score any worker has found so far
+	simsRun   atomic.Int64
+	truncated atomic.Int64
+	scoreSum  atomic.Int64 // Final scores of all rollouts, for Stats.MeanScore
+}
+
+// raiseBest records score as the shared best if it beats the current one.
+func (p *sharedProgress) raiseBest(score int) {
+	for {
+		current := p.bestScore.Load()
+		if int64(score) <= current || p.bestScore.CompareAndSwap(current, int64(score)) {
+			return
+		}
+	}
 }
 
 // --- Worker Function (Updated for "Double Reset" Pattern) ---
-func (s *PuzzleSolver) worker(jobs <-chan Job, results chan<- Result) {
+func (s *PuzzleSolver) worker(ctx context.Context, id int, opts solveOptions, jobs <-chan Job, results chan<- jobResult, shared *sharedProgress) {
 	r := rand.New(rand.NewSource(0))
 
 	// Each worker allocates TWO game objects and reuses them.
 	simulatedGame := s.originalGame.DeepCopy() // For the main simulation
 	tempGame := s.originalGame.DeepCopy()      // A reusable "scratchpad" for testing moves
+	start := s.originalGame.DeepCopy()         // The rollout's starting game, once unknown stones are sampled
+	idleDrawLimit := s.idleDrawLimit()
+	seen := make(map[uint64]bool) // Board positions of the current rollout, for AvoidRepeats
+	sampleUnknowns := s.originalGame.UnknownDrawStones() > 0
 
 	for job := range jobs {
+		s.logf(Debug, "Worker %d: job of %d simulations, seed %d\n", id, job.NumSimulations, job.Seed)
 		r.Seed(job.Seed)
 
-		localBestScore := -1
-		var localBestMoves []game.Move
+		localBest := Result{Score: -1, Cleared: -1, Ranking: math.MinInt}
+		localWorst := Result{Score: math.MaxInt}
 
 		for i := 0; i < job.NumSimulations; i++ {
-			simulatedGame.Reset(s.originalGame)
+			if ctx.Err() != nil {
+				break // Cancelled: report whatever this job found so far
+			}
+			if !opts.seeded && s.TargetScore > 0 && shared.bestScore.Load() >= int64(s.TargetScore) {
+				break // Someone already hit the target; further sims can't do better
+			}
+			if sampleUnknowns {
+				start.Reset(s.originalGame)
+				start.SampleUnknownDrawStones(r)
+				simulatedGame.Reset(start)
+			} else {
+				simulatedGame.Reset(s.originalGame)
+			}
 			movesMade := []game.Move{}
+			for _, move := range opts.prefix {
+				simulatedGame.MakeMove(move.Source, move.Destination)
+				movesMade = append(movesMade, move)
+			}
+			idleDraws := 0
+			if s.AvoidRepeats {
+				clear(seen)
+				seen[simulatedGame.BoardHash()] = true
+			}
+			if len(opts.resume) > 0 && r.Float64() < resumeFraction {
+				// Explore a variation: replay a random prefix of the seed solution, then play on
+				for _, move := range opts.resume[:r.Intn(len(opts.resume)+1)] {
+					if !simulatedGame.IsLegalMove(move) {
+						break // Only possible when unknown draw stones were sampled differently
+					}
+					if !opts.allows(simulatedGame, move) {
+						break
+					}
+					simulatedGame.MakeMove(move.Source, move.Destination)
+					movesMade = append(movesMade, move)
+				}
+			}
 
 			for !simulatedGame.IsSolved() {
+				if len(movesMade) >= s.MaxMoves {
+					shared.truncated.Add(1)
+					break
+				}
 				possibleMoves := s.getPossibleMovesForSimulation(simulatedGame)
+				if opts.forbidden != nil {
+					possibleMoves = allowedMoves(possibleMoves, opts.forbidden)
+				}
+				if opts.noRedraw {
+					possibleMoves = withoutRedraw(simulatedGame, possibleMoves)
+				}
+				if s.AvoidRepeats {
+					possibleMoves = freshMoves(simulatedGame, tempGame, possibleMoves, seen)
+				}
+				if s.AvoidStranding {
+					possibleMoves = unstrandingMoves(simulatedGame, tempGame, possibleMoves)
+				}
 				if len(possibleMoves) == 0 {
 					break
 				}
 
-				var chosenMove game.Move
-				if simulatedGame.CalculateScore() == 0 {
-					chosenMove = possibleMoves[r.Intn(len(possibleMoves))]
-				} else {
-					matchingMoves := []game.Move{}
-					for _, move := range possibleMoves {
-						// *** THE SECOND KEY PERFORMANCE FIX IS HERE ***
-						// Reset the tempGame to the current simulation state.
-						tempGame.Reset(simulatedGame)
-						if tempGame.MakeMove(move.Source, move.Destination) {
-							matchingMoves = append(matchingMoves, move)
-						}
-					}
-					if len(matchingMoves) > 0 && r.Float64() < 0.8 {
-						chosenMove = matchingMoves[r.Intn(len(matchingMoves))]
-					} else {
-						chosenMove = possibleMoves[r.Intn(len(possibleMoves))]
+				chosenMove := s.chooseMove(r, simulatedGame, tempGame, possibleMoves)
+				cleared := simulatedGame.MakeMove(chosenMove.Source, chosenMove.Destination)
+				if s.AvoidRepeats {
+					if len(seen) >= maxRecentStates {
+						clear(seen)
 					}
+					seen[simulatedGame.BoardHash()] = true
 				}
-				simulatedGame.MakeMove(chosenMove.Source, chosenMove.Destination)
 				movesMade = append(movesMade, chosenMove)
-				if len(movesMade) > 200 {
-					break
+				if cleared {
+					idleDraws = 0
+				} else if chosenMove.Source == "DRAW" {
+					idleDraws++
+					if idleDraws > idleDrawLimit {
+						break // Cycling the draw pile with nothing to match; give up on this rollout
+					}
 				}
 			}
 
+			shared.simsRun.Add(1)
 			finalScore := simulatedGame.CalculateScore()
-			if finalScore > localBestScore {
-				localBestScore = finalScore
-				localBestMoves = make([]game.Move, len(movesMade))
-				copy(localBestMoves, movesMade)
+			shared.scoreSum.Add(int64(finalScore))
+			rollout := Result{Score: finalScore, Moves: movesMade, Cleared: clearedCount(simulatedGame),
+				Ranking: s.ranking(simulatedGame, finalScore), Solved: simulatedGame.IsSolved()}
+			if opts.objective.better(rollout, localBest) {
+				localBest = rollout
+				localBest.Moves = copyMoves(movesMade)
+				if sampleUnknowns {
+					localBest.Start = start.DeepCopy()
+				}
+				shared.raiseBest(finalScore)
+			}
+			if opts.trackWorst && finalScore < localWorst.Score {
+				localWorst = rollout
+				localWorst.Moves = copyMoves(movesMade)
+				if sampleUnknowns {
+					localWorst.Start = start.DeepCopy()
+				}
 			}
 		}
-		results <- Result{Score: localBestScore, Moves: localBestMoves}
+		results <- jobResult{best: localBest, worst: localWorst}
+	}
+}
+
+// chooseMove picks the next rollout move in g from possibleMoves. tempGame is scratch space.
+func (s *PuzzleSolver) chooseMove(r *rand.Rand, g, tempGame *game.PuzzleGame, possibleMoves []game.Move) game.Move {
+	if s.SmashGatedThirteens {
+		if smash, ok := gatedSmash(g); ok && containsMove(possibleMoves, smash) {
+			return smash
+		}
+	}
+	if s.Selection == Softmax {
+		return s.softmaxMove(r, g, tempGame, possibleMoves)
+	}
+
+	if g.CalculateScore() == 0 {
+		return possibleMoves[r.Intn(len(possibleMoves))]
+	}
+	matchingMoves := []game.Move{}
+	for _, move := range possibleMoves {
+		// *** THE SECOND KEY PERFORMANCE FIX IS HERE ***
+		// Reset the tempGame to the current simulation state.
+		tempGame.Reset(g)
+		if tempGame.MakeMove(move.Source, move.Destination) {
+			matchingMoves = append(matchingMoves, move)
+		}
+	}
+	if len(matchingMoves) > 0 && r.Float64() < 0.8 {
+		return matchingMoves[r.Intn(len(matchingMoves))]
+	}
+	return possibleMoves[r.Intn(len(possibleMoves))]
+}
+
+// freshMoves drops the moves that clear nothing and lead back to a board position in seen. If
+// that would drop every move, moves is returned unchanged.
+func freshMoves(g, tempGame *game.PuzzleGame, moves []game.Move, seen map[uint64]bool) []game.Move {
+	fresh := make([]game.Move, 0, len(moves))
+	for _, move := range moves {
+		tempGame.Reset(g)
+		if tempGame.MakeMove(move.Source, move.Destination) || !seen[tempGame.BoardHash()] {
+			fresh = append(fresh, move)
+		}
+	}
+	if len(fresh) == 0 {
+		return moves
+	}
+	return fresh
+}
+
+// unstrandingMoves drops the moves that add to g's StrandedStones, when that leaves more than
+// the one HOLD can take out of the pyramid for good. If that would drop every move, moves is
+// returned unchanged.
+func unstrandingMoves(g, tempGame *game.PuzzleGame, moves []game.Move) []game.Move {
+	before := g.StrandedStones()
+	safe := make([]game.Move, 0, len(moves))
+	for _, move := range moves {
+		tempGame.Reset(g)
+		tempGame.MakeMove(move.Source, move.Destination)
+		if after := tempGame.StrandedStones(); after <= before || after <= 1 {
+			safe = append(safe, move)
+		}
+	}
+	if len(safe) == 0 {
+		return moves
+	}
+	return safe
+}
+
+// containsMove reports whether moves includes m.
+func containsMove(moves []game.Move, m game.Move) bool {
+	for _, move := range moves {
+		if move == m {
+			return true
+		}
+	}
+	return false
+}
+
+// gatedSmash returns a smash when smashing 13s is the only way to clear a stone in g, i.e.
+// every clearing move is a smash. Pyramid 13s are preferred over DRW1 since they may uncover
+// the stones they block.
+func gatedSmash(g *game.PuzzleGame) (game.Move, bool) {
+	thirteens := g.AccessibleThirteens()
+	if len(thirteens) == 0 || g.MobilityScore() != len(thirteens) {
+		return game.Move{}, false
+	}
+	// AccessibleThirteens lists DRW1 last, after the pyramid positions
+	return game.Move{Source: thirteens[0], Destination: "SMASH"}, true
+}
+
+// softmaxMove scores each move by playing it on tempGame and samples one with softmaxPick.
+func (s *PuzzleSolver) softmaxMove(r *rand.Rand, g, tempGame *game.PuzzleGame, moves []game.Move) game.Move {
+	scores := make([]int, len(moves))
+	for i, move := range moves {
+		tempGame.Reset(g)
+		tempGame.MakeMove(move.Source, move.Destination)
+		scores[i] = tempGame.CalculateScore()
+	}
+	temperature := s.Temperature
+	if temperature <= 0 {
+		temperature = defaultTemperature
+	}
+	return moves[softmaxPick(r, scores, temperature)]
+}
+
+// softmaxPick returns index i with probability proportional to exp(scores[i]/temperature).
+// Scores are shifted by their maximum first so large scores cannot overflow.
+func softmaxPick(r *rand.Rand, scores []int, temperature float64) int {
+	maxScore := scores[0]
+	for _, score := range scores[1:] {
+		if score > maxScore {
+			maxScore = score
+		}
+	}
+	weights := make([]float64, len(scores))
+	total := 0.0
+	for i, score := range scores {
+		weights[i] = math.Exp(float64(score-maxScore) / temperature)
+		total += weights[i]
+	}
+	pick := r.Float64() * total
+	for i, weight := range weights {
+		pick -= weight
+		if pick < 0 {
+			return i
+		}
 	}
+	return len(scores) - 1
+}
+
+// idleDrawLimit resolves MaxIdleDraws, defaulting to the size of the starting draw pile.
+func (s *PuzzleSolver) idleDrawLimit() int {
+	if s.MaxIdleDraws > 0 {
+		return s.MaxIdleDraws
+	}
+	drawStones := s.originalGame.DrawStonesRemaining()
+	if drawStones < 1 {
+		return 1
+	}
+	return drawStones
 }
 
 // --- Manager Function (Updated for Batching) ---
 func (s *PuzzleSolver) SolveMonteCarlo(iterations int) ([]game.Move, int) {
-	fmt.Printf("Running %d simulations in parallel...\n", iterations)
+	return s.SolveMonteCarloContext(context.Background(), iterations)
+}
 
-	numWorkers := runtime.NumCPU()
-	fmt.Printf("Utilizing %d CPU cores as workers.\n", numWorkers)
+// SolveMonteCarloContext is SolveMonteCarlo with cancellation. When ctx is cancelled the
+// workers stop after their current simulation and the best result found so far is returned.
+func (s *PuzzleSolver) SolveMonteCarloContext(ctx context.Context, iterations int) ([]game.Move, int) {
+	best, _ := s.solveMonteCarlo(ctx, iterations, solveOptions{objective: s.Objective})
+	return best.Moves, best.Score
+}
 
-	jobs := make(chan Job, numWorkers)
-	results := make(chan Result, numWorkers)
+// SolveShortestClear runs a Monte Carlo solve that keeps the shortest rollout clearing the
+// whole pyramid, whatever the solver's Objective. It returns nil and -1 if no rollout cleared
+// the pyramid.
+func (s *PuzzleSolver) SolveShortestClear(iterations int) ([]game.Move, int) {
+	best, _ := s.solveMonteCarlo(context.Background(), iterations, solveOptions{objective: ShortestClear})
+	if best.Cleared != game.TotalPyramidStones {
+		return nil, -1
+	}
+	return best.Moves, best.Score
+}
 
-	for w := 0; w < numWorkers; w++ {
-		go s.worker(jobs, results)
+// SolveMonteCarloWithWorst is SolveMonteCarlo that also returns the lowest-scoring rollout,
+// for studying how rollouts go wrong, such as spiralling through redraws.
+func (s *PuzzleSolver) SolveMonteCarloWithWorst(iterations int) (best []game.Move, bestScore int, worst []game.Move, worstScore int) {
+	bestResult, worstResult := s.solveMonteCarlo(context.Background(), iterations, solveOptions{objective: s.Objective, trackWorst: true})
+	return bestResult.Moves, bestResult.Score, worstResult.Moves, worstResult.Score
+}
+
+// SolveConstrained runs a Monte Carlo solve in which rollouts never play a move for which
+// forbidden returns true, e.g. to solve without ever using HOLD.
+func (s *PuzzleSolver) SolveConstrained(iterations int, forbidden func(game.Move) bool) ([]game.Move, int) {
+	best, _ := s.solveMonteCarlo(context.Background(), iterations, solveOptions{objective: s.Objective, forbidden: forbidden})
+	return best.Moves, best.Score
+}
+
+// SolveNoRedrawScore runs a Monte Carlo solve in which rollouts never redraw the draw pile and
+// returns the best score found, the ceiling before committing to a redraw-heavy strategy, and
+// whether the rollout scoring it cleared the whole pyramid. SeedMoves that redraw do not count
+// towards the score.
+func (s *PuzzleSolver) SolveNoRedrawScore(iterations int) (int, bool) {
+	best, _ := s.solveMonteCarlo(context.Background(), iterations, solveOptions{objective: MaxScore, noRedraw: true})
+	return best.Score, best.Solved
+}
+
+// SolveMonteCarloSeeded is SolveMonteCarlo made reproducible: the same seed, iterations and
+// options give identical moves and score on every run and machine. The simulations are split
+// into a fixed number of jobs seeded from seed rather than one per CPU, the TargetScore early
+// exit is skipped because it depends on timing, and results are merged with the deterministic
+// tie-break of betterSolution, so arrival order does not matter.
+func (s *PuzzleSolver) SolveMonteCarloSeeded(iterations int, seed int64) ([]game.Move, int) {
+	best, _ := s.solveMonteCarlo(context.Background(), iterations, solveOptions{objective: s.Objective, seeded: true, seed: seed})
+	return best.Moves, best.Score
+}
+
+// MoveScore is an opening move with how well rollouts starting with it scored.
+type MoveScore struct {
+	Move      game.Move
+	MeanScore float64 // Mean final score of the rollouts
+	BestScore int     // Best final score of the rollouts
+}
+
+// RankOpeningMoves runs iterationsPerMove Monte Carlo rollouts starting with each legal opening
+// move in turn and returns every opening with its scores, best mean score first. The mean shows
+// how forgiving an opening is, where the best score alone only shows its ceiling. LastStats
+// afterwards describes the solve of the last opening tried.
+func (s *PuzzleSolver) RankOpeningMoves(iterationsPerMove int) []MoveScore {
+	openings := s.originalGame.DistinctFirstMoves()
+	ranking := make([]MoveScore, 0, len(openings))
+	for _, opening := range openings {
+		opts := solveOptions{objective: s.Objective, prefix: []game.Move{opening}}
+		best, _ := s.solveMonteCarlo(context.Background(), iterationsPerMove, opts)
+		ranking = append(ranking, MoveScore{Move: opening, MeanScore: s.LastStats().MeanScore, BestScore: best.Score})
 	}
+	sort.SliceStable(ranking, func(i, j int) bool {
+		return ranking[i].MeanScore > ranking[j].MeanScore
+	})
+	return ranking
+}
 
-	simsPerWorker := iterations / numWorkers
-	baseSeed := time.Now().UnixNano()
-	for w := 0; w < numWorkers; w++ {
-		numSims := simsPerWorker
-		if w == numWorkers-1 {
-			numSims += iterations % numWorkers
+// Plan runs a Monte Carlo solve of iterations simulations and returns the first k moves of the
+// best sequence found, a short lookahead for a player to follow. k is clamped to the length of
+// that sequence. It is an error if the solver's game is already solved or has no legal moves,
+// since there is nothing to plan then. A position where only DRAW is left can still be
+// planned: the next draw stone may give a match.
+func (s *PuzzleSolver) Plan(k, iterations int) ([]game.Move, error) {
+	if s.originalGame.IsSolved() {
+		return nil, fmt.Errorf("the puzzle is already solved")
+	}
+	if len(s.originalGame.LegalMoves()) == 0 {
+		return nil, fmt.Errorf("no legal moves to plan")
+	}
+	moves, _ := s.SolveMonteCarlo(iterations)
+	if k < 0 {
+		k = 0
+	}
+	if k > len(moves) {
+		k = len(moves)
+	}
+	return moves[:k], nil
+}
+
+// plateauBatchSize is how many simulations each batch of SolveUntilPlateau runs.
+const plateauBatchSize = 2000
+
+// SolveUntilPlateau runs Monte Carlo batches of plateauBatchSize simulations until patience
+// batches in a row fail to improve on the best result. It always runs at least one batch and
+// minIterations simulations, and stops early once a result reaches TargetScore. LastStats
+// reports the total across all batches, with SimulationsRequested set to the simulations run.
+func (s *PuzzleSolver) SolveUntilPlateau(minIterations, patience int) ([]game.Move, int) {
+	opts := solveOptions{objective: s.Objective}
+	best := Result{Score: -1, Moves: []game.Move{}, Cleared: -1, Ranking: math.MinInt}
+	total := Stats{}
+	for stagnant := 0; total.SimulationsRun == 0 || total.SimulationsRun < minIterations || stagnant < patience; {
+		result, _ := s.solveMonteCarlo(context.Background(), plateauBatchSize, opts)
+		batch := s.LastStats()
+		total.SimulationsRun += batch.SimulationsRun
+		total.Truncated += batch.Truncated
+		if opts.objective.better(result, best) {
+			best = result
+			stagnant = 0
+		} else {
+			stagnant++
+		}
+		if s.TargetScore > 0 && best.Score >= s.TargetScore {
+			break
+		}
+	}
+
+	total.SimulationsRequested = total.SimulationsRun
+	total.Solved = best.Solved
+	s.setLastRun(total, best)
+	return best.Moves, best.Score
+}
+
+// seededJobs is how many jobs a seeded solve is split into, whatever the CPU count.
+const seededJobs = 64
+
+// solveOptions are the per-solve settings passed down to the workers.
+type solveOptions struct {
+	objective  Objective
+	forbidden  func(game.Move) bool // Moves rollouts must not play; nil allows all
+	seeded     bool                 // Reproducible solve seeded from seed, see SolveMonteCarloSeeded
+	seed       int64
+	resume     []game.Move // SeedMoves, once checked to replay legally, up to any disallowed move
+	prefix     []game.Move // Legal moves every rollout starts with
+	trackWorst bool        // Also find the lowest-scoring rollout
+	noRedraw   bool        // Rollouts never play the DRAW that starts a redraw
+}
+
+// allows reports whether rollouts may play move in g under these options.
+func (o solveOptions) allows(g *game.PuzzleGame, move game.Move) bool {
+	if o.forbidden != nil && o.forbidden(move) {
+		return false
+	}
+	return !o.noRedraw || !startsRedraw(g, move)
+}
+
+// firstDisallowed returns the index of the first of moves, played in order from g, that the
+// options do not allow, or -1 if they allow them all. moves must replay legally up to there.
+func (o solveOptions) firstDisallowed(g *game.PuzzleGame, moves []game.Move) int {
+	replayed := g.DeepCopy()
+	for i, move := range moves {
+		if !o.allows(replayed, move) {
+			return i
+		}
+		replayed.MakeMove(move.Source, move.Destination)
+	}
+	return -1
+}
+
+// jobResult is what a worker reports for one job: its best rollout and, with
+// solveOptions.trackWorst, its lowest-scoring one.
+type jobResult struct {
+	best, worst Result
+}
+
+// partitionJobs splits iterations into at most numJobs jobs seeded baseSeed, baseSeed+1, ...
+// The last job takes the remainder, and jobs with nothing to simulate are dropped.
+func partitionJobs(iterations, numJobs int, baseSeed int64) []Job {
+	jobList := []Job{}
+	simsPerJob := iterations / numJobs
+	for j := 0; j < numJobs; j++ {
+		numSims := simsPerJob
+		if j == numJobs-1 {
+			numSims += iterations % numJobs
 		}
 		if numSims > 0 {
-			jobs <- Job{NumSimulations: numSims, Seed: baseSeed + int64(w)}
+			jobList = append(jobList, Job{NumSimulations: numSims, Seed: baseSeed + int64(j)})
 		}
 	}
-	close(jobs)
+	return jobList
+}
 
-	fmt.Println("All jobs distributed. Collecting results...")
-	activeWorkers := numWorkers
-	// Adjust loop to handle cases where numSims was 0 for some workers
-	for w := 0; w < numWorkers; w++ {
-		if (iterations/numWorkers > 0) || (w < iterations%numWorkers) {
-			result := <-results
-			activeWorkers--
-			fmt.Printf("\rResult received. Waiting for %d more workers...", activeWorkers)
-			if result.Score > s.bestScore {
-				s.bestScore = result.Score
-				s.bestMoves = result.Moves
-			}
+// startsRedraw reports whether playing move in g would run off the end of the draw pile and
+// redraw it.
+func startsRedraw(g *game.PuzzleGame, move game.Move) bool {
+	return move.Source == "DRAW" && g.CurrentSegment()+1 >= g.NumActiveSegments()
+}
+
+// withoutRedraw returns moves without the DRAW that would start a redraw in g.
+func withoutRedraw(g *game.PuzzleGame, moves []game.Move) []game.Move {
+	kept := make([]game.Move, 0, len(moves))
+	for _, move := range moves {
+		if !startsRedraw(g, move) {
+			kept = append(kept, move)
 		}
 	}
-	fmt.Println("\nCollection complete.")
+	return kept
+}
 
-	return s.bestMoves, s.bestScore
+// allowedMoves returns the moves for which forbidden returns false.
+func allowedMoves(moves []game.Move, forbidden func(game.Move) bool) []game.Move {
+	allowed := moves[:0]
+	for _, move := range moves {
+		if !forbidden(move) {
+			allowed = append(allowed, move)
+		}
+	}
+	return allowed
 }
 
-// getPossibleMovesForSimulation remains unchanged.
-func (s *PuzzleSolver) getPossibleMovesForSimulation(g *game.PuzzleGame) []game.Move {
-	moves := []game.Move{{Source: "DRAW", Destination: "DRAW"}}
-	accessiblePositions := g.GetAccessiblePositions()
-	for _, pos := range accessiblePositions {
-		row, col, _ := utils.StringToIndices(pos)
-		if g.PyramidValue(row, col) == 13 {
-			moves = append(moves, game.Move{Source: pos, Destination: "SMASH"})
-		}
-	}
-	for i, pos1 := range accessiblePositions {
-		row1, col1, _ := utils.StringToIndices(pos1)
-		stone1 := g.PyramidValue(row1, col1)
-		if stone1 == 13 {
-			continue
-		}
-		for j := i + 1; j < len(accessiblePositions); j++ {
-			pos2 := accessiblePositions[j]
-			row2, col2, _ := utils.StringToIndices(pos2)
-			stone2 := g.PyramidValue(row2, col2)
-			if g.IsMatchingPair(stone1, stone2) {
-				moves = append(moves, game.Move{Source: pos1, Destination: pos2})
-			}
+// solveMonteCarlo runs the parallel Monte Carlo search with opts and returns the best result
+// and, with opts.trackWorst, the lowest-scoring one, both with canonicalized moves. Without
+// trackWorst the worst result has no moves and a score of -1.
+func (s *PuzzleSolver) solveMonteCarlo(ctx context.Context, iterations int, opts solveOptions) (best, worst Result) {
+	s.logf(Normal, "Running %d simulations in parallel...\n", iterations)
+
+	numWorkers := runtime.NumCPU()
+	s.logf(Normal, "Utilizing %d CPU cores as workers.\n", numWorkers)
+
+	numJobs, baseSeed := numWorkers, time.Now().UnixNano()
+	if opts.seeded {
+		// A fixed partition makes the jobs, and so the result, independent of the CPU count
+		numJobs, baseSeed = seededJobs, opts.seed
+	}
+	jobList := partitionJobs(iterations, numJobs, baseSeed)
+
+	best = Result{Score: -1, Moves: []game.Move{}, Cleared: -1, Ranking: math.MinInt}
+	worst = Result{Score: math.MaxInt}
+	if len(s.SeedMoves) > 0 && len(opts.prefix) == 0 {
+		seedGame, err := Replay(s.originalGame, s.SeedMoves)
+		disallowed := -1
+		if err == nil {
+			disallowed = opts.firstDisallowed(s.originalGame, s.SeedMoves)
 		}
-		if g.HoldValue() != -1 && g.IsMatchingPair(stone1, g.HoldValue()) {
-			moves = append(moves, game.Move{Source: pos1, Destination: "HOLD"})
+		switch {
+		case errors.Is(err, ErrUnknownDrawStones):
+			// It can't be scored without the pile it was found on, but rollouts whose sampled
+			// pile lets them can still follow it
+			s.logf(Normal, "Not scoring seed solution: %v\n", err)
+			opts.resume = copyMoves(s.SeedMoves)
+		case err != nil:
+			s.logf(Normal, "Ignoring seed solution: %v\n", err)
+		case disallowed != -1:
+			// Rollouts may still follow it up to the move this solve rules out
+			s.logf(Normal, "Ignoring seed solution: move %d is not allowed in this solve\n", disallowed)
+			opts.resume = copyMoves(s.SeedMoves[:disallowed])
+		default:
+			seedScore := seedGame.CalculateScore()
+			best = Result{Score: seedScore, Moves: copyMoves(s.SeedMoves), Cleared: clearedCount(seedGame),
+				Ranking: s.ranking(seedGame, seedScore), Solved: seedGame.IsSolved()}
+			opts.resume = best.Moves
 		}
-		drw1Stone := g.GetCurrentDrawStone()
-		if drw1Stone != -1 && g.IsMatchingPair(stone1, drw1Stone) {
-			moves = append(moves, game.Move{Source: pos1, Destination: "DRW1"})
+	}
+
+	// Both channels hold every job, so neither side blocks however many jobs there are
+	jobs := make(chan Job, len(jobList))
+	results := make(chan jobResult, len(jobList))
+	shared := &sharedProgress{}
+	shared.bestScore.Store(int64(best.Score))
+
+	for w := 0; w < numWorkers; w++ {
+		go s.worker(ctx, w, opts, jobs, results, shared)
+	}
+	for _, job := range jobList {
+		jobs <- job
+	}
+	close(jobs)
+	jobsSent := len(jobList)
+
+	s.logf(Normal, "All jobs distributed. Collecting results...\n")
+	stopProgress := s.reportProgress(shared, iterations)
+	// Every job reports back exactly once
+	pendingJobs := jobsSent
+	for j := 0; j < jobsSent; j++ {
+		result := <-results
+		pendingJobs--
+		s.logf(Normal, "\rResult received. Waiting for %d more jobs...", pendingJobs)
+		if opts.objective.better(result.best, best) {
+			best = result.best
 		}
-		if g.HoldValue() == -1 && stone1 != 13 {
-			moves = append(moves, game.Move{Source: pos1, Destination: "HOLD"})
+		if result.worst.Moves != nil && result.worst.Score < worst.Score {
+			worst = result.worst
 		}
 	}
-	if g.HoldValue() != -1 && g.GetCurrentDrawStone() != -1 && g.IsMatchingPair(g.HoldValue(), g.GetCurrentDrawStone()) {
-		moves = append(moves, game.Move{Source: "HOLD", Destination: "DRW1"})
+	stopProgress()
+	s.logf(Normal, "\nCollection complete.\n")
+
+	stats := Stats{
+		SimulationsRequested: iterations,
+		SimulationsRun:       int(shared.simsRun.Load()),
+		Truncated:            int(shared.truncated.Load()),
+		Solved:               best.Solved,
+	}
+	if stats.SimulationsRun > 0 {
+		stats.MeanScore = float64(shared.scoreSum.Load()) / float64(stats.SimulationsRun)
 	}
-	if g.HoldValue() == -1 && g.GetCurrentDrawStone() != -1 && g.GetCurrentDrawStone() != 13 {
-		moves = append(moves, game.Move{Source: "DRW1", Destination: "HOLD"})
+	s.setLastRun(stats, best)
+	if stats.SimulationsRun < iterations {
+		s.logf(Normal, "Stopped early after %d of %d simulations.\n", stats.SimulationsRun, iterations)
 	}
-	if g.GetCurrentDrawStone() == 13 {
-		moves = append(moves, game.Move{Source: "DRW1", Destination: "SMASH"})
+	if stats.Truncated > 0 {
+		s.logf(Normal, "%d of %d rollouts hit the %d-move cap, so scores may be capped rather than stuck.\n",
+			stats.Truncated, stats.SimulationsRun, s.MaxMoves)
+	}
+
+	best.Moves = canonicalMoves(best.Moves)
+	if worst.Moves == nil {
+		worst = Result{Score: -1, Moves: []game.Move{}, Cleared: -1, Ranking: math.MinInt}
+	}
+	worst.Moves = canonicalMoves(worst.Moves)
+	return best, worst
+}
+
+// SolveMonteCarloGame is SolveMonteCarlo returning the best rollout's final game state instead
+// of its moves, for callers that want to inspect the finished board. The state is rebuilt by
+// replaying the best moves on a copy of the game the rollout started from, with any unknown
+// draw stones as it sampled them; games are deterministic, so it matches the rollout exactly.
+func (s *PuzzleSolver) SolveMonteCarloGame(iterations int) (*game.PuzzleGame, int) {
+	best, _ := s.solveMonteCarlo(context.Background(), iterations, solveOptions{objective: s.Objective})
+	final := s.originalGame.DeepCopy()
+	if best.Start != nil {
+		final = best.Start.DeepCopy()
+	}
+	for _, move := range best.Moves {
+		final.MakeMove(move.Source, move.Destination)
+	}
+	return final, best.Score
+}
+
+// reportProgress starts calling s.Progress every progressInterval, if it is set, and logging
+// the best score at Debug verbosity. It returns a function that stops the reports and waits for
+// the last one to finish.
+func (s *PuzzleSolver) reportProgress(shared *sharedProgress, iterations int) (stop func()) {
+	if s.Progress == nil && s.Verbosity < Debug {
+		return func() {}
+	}
+	start := time.Now()
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if s.Progress != nil {
+					s.Progress(int(shared.simsRun.Load()), iterations, time.Since(start))
+				}
+				s.logf(Debug, "\nBest score so far: %d\n", shared.bestScore.Load())
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// calibrationSims is how many rollouts EstimateSolveTime times.
+const calibrationSims = 1000
+
+// EstimateSolveTime predicts how long a Monte Carlo solve of iterations simulations will take
+// by timing calibrationSims rollouts on a single worker and scaling up to iterations spread over
+// one worker per CPU. The calibration itself takes a fraction of a second on a typical puzzle.
+// Rollout lengths vary, so treat the result as a rough figure.
+func (s *PuzzleSolver) EstimateSolveTime(iterations int) time.Duration {
+	return extrapolateSolveTime(s.timeRollouts(calibrationSims), iterations, runtime.NumCPU())
+}
+
+// timeRollouts runs sims rollouts on one worker and returns the mean time per rollout.
+func (s *PuzzleSolver) timeRollouts(sims int) time.Duration {
+	jobs := make(chan Job, 1)
+	results := make(chan jobResult, 1)
+	jobs <- Job{NumSimulations: sims, Seed: time.Now().UnixNano()}
+	close(jobs)
+	start := time.Now()
+	// Seeded skips the TargetScore exit, which would cut the calibration short
+	s.worker(context.Background(), 0, solveOptions{objective: s.Objective, seeded: true}, jobs, results, &sharedProgress{})
+	return time.Since(start) / time.Duration(sims)
+}
+
+// extrapolateSolveTime scales a per-rollout time to iterations rollouts shared by workers.
+func extrapolateSolveTime(perRollout time.Duration, iterations, workers int) time.Duration {
+	if workers < 1 {
+		workers = 1
+	}
+	return perRollout * time.Duration(iterations) / time.Duration(workers)
+}
+
+// EstimateRemaining extrapolates how much longer a solve will take from the fraction of it
+// completed so far and the time that took, assuming a constant rate. It returns 0 once the
+// fraction reaches 1 and -1 while nothing has completed, since no rate is known yet.
+func EstimateRemaining(fraction float64, elapsed time.Duration) time.Duration {
+	if fraction <= 0 {
+		return -1
+	}
+	if fraction >= 1 {
+		return 0
+	}
+	return time.Duration(float64(elapsed) * (1 - fraction) / fraction)
+}
+
+// getPossibleMovesForSimulation returns the moves a rollout may consider in g: the legal moves,
+// less poor HOLD parks under PruneHoldParks.
+func (s *PuzzleSolver) getPossibleMovesForSimulation(g *game.PuzzleGame) []game.Move {
+	moves := g.LegalMoves()
+	if s.PruneHoldParks && g.HoldValue() == -1 {
+		moves = prunedHoldParks(g, moves)
 	}
 	return moves
 }
 
+// prunedHoldParks drops the moves parking a pyramid stone in g's empty HOLD whose
+// HoldValueEstimate is below minHoldParkValue.
+func prunedHoldParks(g *game.PuzzleGame, moves []game.Move) []game.Move {
+	kept := moves[:0]
+	for _, move := range moves {
+		park := move.Destination == "HOLD" && move.Source != "DRW1"
+		if !park || g.HoldValueEstimate(move.Source) >= minHoldParkValue {
+			kept = append(kept, move)
+		}
+	}
+	return kept
+}
+
 // --- Helper functions for accessing game state ---
 func (s *PuzzleSolver) IsMatchingPair(stone1, stone2 int) bool {
 	return s.originalGame.IsMatchingPair(stone1, stone2)
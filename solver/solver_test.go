@@ -0,0 +1,720 @@
+package solver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pyramid_solver_go_local/game"
+	"pyramid_solver_go_local/utils"
+)
+
+// examplePuzzle returns the puzzle main.go sets up as its example.
+func examplePuzzle(t testing.TB) *game.PuzzleGame {
+	t.Helper()
+	g := game.NewPuzzleGame()
+	pyramid := []int{12, 10, 11, 6, 11, 7, 12, 11, 5, 1, 4, 1, 4, 5, 10, 8, 11, 9, 7, 2, 9, 6, 2, 13, 9, 10, 12, 13}
+	drawPile := []int{6, 3, 8, 9, 3, 10, 2, 13, 6, 7, 1, 13, 12, 4, 1, 2, 3, 8, 5, 3, 5, 7, 3, 8}
+	if err := g.SetupCustomGame(pyramid, drawPile); err != nil {
+		t.Fatalf("SetupCustomGame: %v", err)
+	}
+	return g
+}
+
+// quietSolver returns a solver for g that prints nothing.
+func quietSolver(g *game.PuzzleGame) *PuzzleSolver {
+	s := NewPuzzleSolver(g)
+	s.Verbosity = Quiet
+	return s
+}
+
+func TestSolveUntilPlateauStopsBeforeCap(t *testing.T) {
+	s := quietSolver(examplePuzzle(t))
+	const patience = 2
+	s.SolveUntilPlateau(0, patience)
+
+	stats := s.LastStats()
+	if stats.SimulationsRun > 100*plateauBatchSize {
+		t.Errorf("ran %d simulations, want a plateau well before %d", stats.SimulationsRun, 100*plateauBatchSize)
+	}
+	if stats.SimulationsRequested != stats.SimulationsRun {
+		t.Errorf("SimulationsRequested = %d, want SimulationsRun %d", stats.SimulationsRequested, stats.SimulationsRun)
+	}
+}
+
+func TestSolveUntilPlateauZeroTargetRunsPatienceBatches(t *testing.T) {
+	s := quietSolver(examplePuzzle(t))
+	s.TargetScore = 0
+	const patience = 3
+	s.SolveUntilPlateau(0, patience)
+
+	if got, want := s.LastStats().SimulationsRun, patience*plateauBatchSize; got < want {
+		t.Errorf("ran %d simulations with TargetScore 0, want at least %d batches (%d)", got, patience, want)
+	}
+}
+
+// seedSolution returns a reproducible solution of the example puzzle that parks stones in
+// HOLD and redraws twice.
+func seedSolution(t testing.TB) ([]game.Move, int) {
+	t.Helper()
+	moves, score := quietSolver(examplePuzzle(t)).SolveMonteCarloSeeded(3000, 1)
+	final, err := Replay(examplePuzzle(t), moves)
+	if err != nil {
+		t.Fatalf("seed solution does not replay: %v", err)
+	}
+	if final.Redraws() == 0 || !containsMoveTo(moves, "HOLD") {
+		t.Fatalf("seed solution %s should redraw and use HOLD", game.EncodeMoves(moves))
+	}
+	return moves, score
+}
+
+// containsMoveTo reports whether any of moves has dst as its destination.
+func containsMoveTo(moves []game.Move, dst string) bool {
+	for _, move := range moves {
+		if move.Destination == dst {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSeedMovesNeverWorse(t *testing.T) {
+	seed, seedScore := seedSolution(t)
+	s := quietSolver(examplePuzzle(t))
+	s.SeedMoves = seed
+	if _, score := s.SolveMonteCarlo(200); score < seedScore {
+		t.Errorf("seeded solve scored %d, worse than the seed's %d", score, seedScore)
+	}
+}
+
+func TestSeedMovesRespectForbidden(t *testing.T) {
+	seed, _ := seedSolution(t)
+	s := quietSolver(examplePuzzle(t))
+	s.SeedMoves = seed
+	noHold := func(m game.Move) bool { return m.Source == "HOLD" || m.Destination == "HOLD" }
+	moves, _ := s.SolveConstrained(10, noHold)
+	for i, move := range moves {
+		if noHold(move) {
+			t.Fatalf("move %d (%s) uses HOLD despite being forbidden", i, game.EncodeMoves([]game.Move{move}))
+		}
+	}
+}
+
+func TestNoRedrawSolveIgnoresRedrawingSeed(t *testing.T) {
+	seed, _ := seedSolution(t)
+	s := quietSolver(examplePuzzle(t))
+	s.SeedMoves = seed
+	best, _ := s.solveMonteCarlo(context.Background(), 500, solveOptions{objective: MaxScore, noRedraw: true})
+
+	final, err := Replay(examplePuzzle(t), best.Moves)
+	if err != nil {
+		t.Fatalf("best moves do not replay: %v", err)
+	}
+	if final.Redraws() != 0 {
+		t.Errorf("best moves redraw %d times, want 0", final.Redraws())
+	}
+	if final.CalculateScore() != best.Score {
+		t.Errorf("best moves score %d on replay, reported %d", final.CalculateScore(), best.Score)
+	}
+}
+
+func TestSolveNoRedrawScoreIgnoresRedrawingSeed(t *testing.T) {
+	seed, seedScore := seedSolution(t)
+	s := quietSolver(examplePuzzle(t))
+	s.SeedMoves = seed
+	// Without redraws the example puzzle cannot get near the seed's score
+	if score, _ := s.SolveNoRedrawScore(500); score >= seedScore {
+		t.Errorf("SolveNoRedrawScore = %d, want below the redrawing seed's %d", score, seedScore)
+	}
+}
+
+func TestPlanIsLegalPrefix(t *testing.T) {
+	const k = 5
+	plan, err := quietSolver(examplePuzzle(t)).Plan(k, 500)
+	if err != nil {
+		t.Fatalf("Plan: %v", err)
+	}
+	if len(plan) == 0 || len(plan) > k {
+		t.Fatalf("plan has %d moves, want 1 to %d", len(plan), k)
+	}
+	if _, err := Replay(examplePuzzle(t), plan); err != nil {
+		t.Errorf("plan %s does not replay: %v", game.EncodeMoves(plan), err)
+	}
+}
+
+// uniformPuzzle sets up a pyramid of 28 copies of stone with the given draw pile.
+func uniformPuzzle(t testing.TB, stone int, drawPile []int) *game.PuzzleGame {
+	t.Helper()
+	pyramid := make([]int, game.TotalPyramidStones)
+	for i := range pyramid {
+		pyramid[i] = stone
+	}
+	g := game.NewPuzzleGame()
+	if err := g.SetupCustomGame(pyramid, drawPile); err != nil {
+		t.Fatalf("SetupCustomGame: %v", err)
+	}
+	return g
+}
+
+func TestPlanWithNothingToPlan(t *testing.T) {
+	solved := uniformPuzzle(t, 13, nil)
+	for !solved.IsSolved() {
+		solved.MakeMove(solved.GetAccessiblePositions()[0], "SMASH")
+	}
+	// 7s never match each other, and with a 7 in HOLD and no draw pile nothing is legal
+	stuck := uniformPuzzle(t, 7, nil)
+	stuck.MakeMove("A1", "HOLD")
+
+	for name, g := range map[string]*game.PuzzleGame{"solved": solved, "no legal moves": stuck} {
+		if plan, err := quietSolver(g).Plan(3, 100); err == nil {
+			t.Errorf("%s: Plan = %s, want an error", name, game.EncodeMoves(plan))
+		}
+	}
+}
+
+func TestPlanDrawOnly(t *testing.T) {
+	// 7s never match each other, and with a 1 in HOLD and another as DRW1 only DRAW is left,
+	// but the draw brings up the 2 that matches the 1 in HOLD
+	g := uniformPuzzle(t, 7, []int{1, 1, 1, 2})
+	g.MakeMove("DRW1", "HOLD")
+	if moves := g.LegalMoves(); len(moves) != 1 || moves[0].Source != "DRAW" {
+		t.Fatalf("LegalMoves() = %v, want only DRAW", moves)
+	}
+	plan, err := quietSolver(g).Plan(1, 100)
+	if err != nil {
+		t.Fatalf("Plan of a draw-only position: %v", err)
+	}
+	if len(plan) != 1 || plan[0].Source != "DRAW" {
+		t.Errorf("Plan = %s, want DRAW", game.EncodeMoves(plan))
+	}
+}
+
+func TestSolveMonteCarloContextCancelKeepsBestSoFar(t *testing.T) {
+	s := NewPuzzleSolver(examplePuzzle(t))
+	var out bytes.Buffer
+	s.Output = &out
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	const iterations = 100_000_000
+	moves, score := s.SolveMonteCarloContext(ctx, iterations)
+	if run := s.LastStats().SimulationsRun; run == 0 || run >= iterations {
+		t.Fatalf("cancelled solve ran %d of %d simulations, want some but not all", run, iterations)
+	}
+	final, err := Replay(examplePuzzle(t), moves)
+	if err != nil {
+		t.Fatalf("partial best does not replay: %v", err)
+	}
+	if final.CalculateScore() != score {
+		t.Errorf("partial best replays to %d, reported %d", final.CalculateScore(), score)
+	}
+	if !strings.Contains(out.String(), "Stopped early after") {
+		t.Errorf("output does not report the early stop:\n%s", out.String())
+	}
+}
+
+func TestTargetScoreStopsSolveEarly(t *testing.T) {
+	s := quietSolver(examplePuzzle(t))
+	s.TargetScore = 1 // Any rollout that clears a stone reaches it
+	const iterations = 10_000_000
+	_, score := s.SolveMonteCarlo(iterations)
+
+	stats := s.LastStats()
+	if score < s.TargetScore {
+		t.Errorf("best score %d is below the target %d", score, s.TargetScore)
+	}
+	if stats.SimulationsRequested != iterations {
+		t.Errorf("SimulationsRequested = %d, want %d", stats.SimulationsRequested, iterations)
+	}
+	if stats.SimulationsRun >= iterations {
+		t.Errorf("ran all %d simulations despite reaching the target", stats.SimulationsRun)
+	}
+}
+
+func TestBetterSolutionTieBreak(t *testing.T) {
+	short, _ := game.DecodeMoves("A1-A5 A3-A7")
+	long, _ := game.DecodeMoves("A1-A5 DRAW A3-A7")
+	other, _ := game.DecodeMoves("A3-A7 A1-A5")
+
+	tests := []struct {
+		name       string
+		score      int
+		moves      []game.Move
+		bestScore  int
+		bestMoves  []game.Move
+		wantBetter bool
+	}{
+		{"higher score wins even if longer", 200, long, 100, short, true},
+		{"equal score, shorter wins", 100, short, 100, long, true},
+		{"equal score, longer loses", 100, long, 100, short, false},
+		{"equal score and length, smaller encoding wins", 100, short, 100, other, true},
+		{"identical is not better", 100, short, 100, short, false},
+	}
+	for _, tt := range tests {
+		if got := betterSolution(tt.score, tt.moves, tt.bestScore, tt.bestMoves); got != tt.wantBetter {
+			t.Errorf("%s: betterSolution = %v, want %v", tt.name, got, tt.wantBetter)
+		}
+	}
+}
+
+func TestIdleDrawLimitEndsDrawOnlyRollouts(t *testing.T) {
+	// 7s never match and the 1s in HOLD and the draw pile never match each other, so rollouts
+	// can only keep drawing
+	g := uniformPuzzle(t, 7, []int{1, 1, 1, 1, 1, 1, 1, 1, 1})
+	g.MakeMove("DRW1", "HOLD")
+	s := quietSolver(g)
+	start := time.Now()
+	moves, _ := s.SolveMonteCarlo(1000)
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("draw-only solve took %v", elapsed)
+	}
+	if truncated := s.LastStats().Truncated; truncated != 0 {
+		t.Errorf("%d rollouts ran into the move cap instead of the idle-draw limit", truncated)
+	}
+	if limit := s.idleDrawLimit(); len(moves) > limit+1 {
+		t.Errorf("best rollout drew %d times, want at most %d", len(moves), limit+1)
+	}
+}
+
+func TestLowMaxMovesTruncatesRollouts(t *testing.T) {
+	s := quietSolver(examplePuzzle(t))
+	s.MaxMoves = 3 // Far too short to clear the example
+	const iterations = 200
+	moves, _ := s.SolveMonteCarlo(iterations)
+
+	if truncated := s.LastStats().Truncated; truncated != iterations {
+		t.Errorf("Truncated = %d with MaxMoves %d, want all %d rollouts", truncated, s.MaxMoves, iterations)
+	}
+	if len(moves) > s.MaxMoves {
+		t.Errorf("best rollout has %d moves, past the cap of %d", len(moves), s.MaxMoves)
+	}
+
+	// Smashing a pyramid of 13s takes exactly one move per stone, and nothing else is legal
+	exact := quietSolver(uniformPuzzle(t, 13, nil))
+	exact.MaxMoves = game.TotalPyramidStones
+	exact.SolveMonteCarlo(iterations)
+	if stats := exact.LastStats(); !stats.Solved || stats.Truncated != 0 {
+		t.Errorf("solving on the last allowed move: Solved %v, Truncated %d, want solved with none truncated",
+			stats.Solved, stats.Truncated)
+	}
+	exact.MaxMoves = game.TotalPyramidStones - 1
+	exact.SolveMonteCarlo(iterations)
+	if stats := exact.LastStats(); stats.Solved || stats.Truncated != iterations {
+		t.Errorf("one move short of a clear: Solved %v, Truncated %d, want unsolved with all %d truncated",
+			stats.Solved, stats.Truncated, iterations)
+	}
+}
+
+func TestObjectiveBetter(t *testing.T) {
+	moves, _ := game.DecodeMoves("A1-A5 A3-A7")
+	full := Result{Score: 3000, Moves: moves, Cleared: game.TotalPyramidStones, Ranking: 3000, Solved: true}
+	partial := Result{Score: 4000, Moves: moves, Cleared: 26, Ranking: 4000}
+
+	if MaxScore.better(full, partial) {
+		t.Error("MaxScore prefers a full clear over a higher-scoring partial one")
+	}
+	if !MaxClear.better(full, partial) || MaxClear.better(partial, full) {
+		t.Error("MaxClear does not prefer the full clear over the higher-scoring partial one")
+	}
+	higher := partial
+	higher.Cleared = full.Cleared
+	if !MaxClear.better(higher, full) {
+		t.Error("MaxClear does not break a tie in stones cleared by score")
+	}
+}
+
+func TestConcurrentSolvesOnOneSolver(t *testing.T) {
+	s := quietSolver(examplePuzzle(t))
+	const solves = 2
+	var wg sync.WaitGroup
+	moves := make([][]game.Move, solves)
+	scores := make([]int, solves)
+	for i := 0; i < solves; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			moves[i], scores[i] = s.SolveMonteCarlo(300)
+			s.LastStats()
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range moves {
+		final, err := Replay(examplePuzzle(t), moves[i])
+		if err != nil {
+			t.Fatalf("solve %d: moves do not replay: %v", i, err)
+		}
+		if final.CalculateScore() != scores[i] {
+			t.Errorf("solve %d: moves replay to %d, reported %d", i, final.CalculateScore(), scores[i])
+		}
+	}
+}
+
+func TestSoftmaxPickTemperature(t *testing.T) {
+	scores := []int{0, 100, 500}
+	const picks = 3000
+	count := func(temperature float64) [3]int {
+		r := rand.New(rand.NewSource(1))
+		var counts [3]int
+		for i := 0; i < picks; i++ {
+			counts[softmaxPick(r, scores, temperature)]++
+		}
+		return counts
+	}
+
+	if cold := count(1); cold[2] != picks {
+		t.Errorf("at temperature 1 the best move was picked %d of %d times, want every time", cold[2], picks)
+	}
+	hot := count(1e6)
+	for i, n := range hot {
+		if n < picks/3-picks/10 || n > picks/3+picks/10 {
+			t.Errorf("at a very high temperature move %d was picked %d of %d times, want about a third", i, n, picks)
+		}
+	}
+}
+
+func TestSolveMonteCarloGameReturnsSolvedState(t *testing.T) {
+	g := uniformPuzzle(t, 13, []int{1, 2, 3})
+	final, score := quietSolver(g).SolveMonteCarloGame(50)
+	if !final.IsSolved() {
+		t.Errorf("final state of a pyramid of 13s is not solved")
+	}
+	if final.CalculateScore() != score {
+		t.Errorf("final state scores %d, reported %d", final.CalculateScore(), score)
+	}
+	if g.IsSolved() {
+		t.Error("SolveMonteCarloGame changed the puzzle")
+	}
+}
+
+func TestSolveShortestClear(t *testing.T) {
+	g := uniformPuzzle(t, 13, []int{1, 2, 3, 4, 5, 6})
+	moves, score := quietSolver(g).SolveShortestClear(200)
+	if moves == nil {
+		t.Fatal("SolveShortestClear found no clear of a pyramid of 13s")
+	}
+	if len(moves) != game.TotalPyramidStones {
+		t.Errorf("shortest clear has %d moves (%s), want the %d smashes", len(moves), game.EncodeMoves(moves), game.TotalPyramidStones)
+	}
+	final, err := Replay(g, moves)
+	if err != nil || !final.IsSolved() || final.CalculateScore() != score {
+		t.Errorf("shortest clear does not replay to a solved game scoring %d (err %v)", score, err)
+	}
+
+	// 7s never clear, so nothing can empty this pyramid
+	if moves, score := quietSolver(uniformPuzzle(t, 7, []int{1})).SolveShortestClear(50); moves != nil || score != -1 {
+		t.Errorf("SolveShortestClear on an unclearable pyramid = %s, %d, want nil, -1", game.EncodeMoves(moves), score)
+	}
+}
+
+func TestEstimateRemaining(t *testing.T) {
+	tests := []struct {
+		fraction float64
+		elapsed  time.Duration
+		want     time.Duration
+	}{
+		{0.25, 10 * time.Second, 30 * time.Second},
+		{0.5, 10 * time.Second, 10 * time.Second},
+		{1, 10 * time.Second, 0},
+		{0, 10 * time.Second, -1},
+	}
+	for _, tt := range tests {
+		if got := EstimateRemaining(tt.fraction, tt.elapsed); got != tt.want {
+			t.Errorf("EstimateRemaining(%v, %v) = %v, want %v", tt.fraction, tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+func TestObjectiveWeightsTimeBonus(t *testing.T) {
+	moves, _ := game.DecodeMoves("A1-A5")
+	// fast earns its lead from the time bonus, steady from matches and streaks
+	fast := game.ScoreBreakdown{Matches: 500, TimeBonus: 600}
+	steady := game.ScoreBreakdown{Matches: 700, Streak: 200}
+	pick := func(w ObjectiveWeights) string {
+		a := Result{Moves: moves, Ranking: w.apply(fast)}
+		b := Result{Moves: moves, Ranking: w.apply(steady)}
+		if MaxScore.better(a, b) {
+			return "fast"
+		}
+		return "steady"
+	}
+
+	if got := pick(DefaultObjectiveWeights); got != "fast" {
+		t.Errorf("default weights chose the %s rollout, want fast", got)
+	}
+	noTime := DefaultObjectiveWeights
+	noTime.TimeBonus = 0
+	if got := pick(noTime); got != "steady" {
+		t.Errorf("weights ignoring the time bonus chose the %s rollout, want steady", got)
+	}
+}
+
+func TestSolveConstrainedForbidsSmash(t *testing.T) {
+	noSmash := func(m game.Move) bool { return m.Destination == "SMASH" }
+	moves, score := quietSolver(examplePuzzle(t)).SolveConstrained(300, noSmash)
+	for i, move := range moves {
+		if noSmash(move) {
+			t.Fatalf("move %d (%s) smashes despite being forbidden", i, game.EncodeMoves([]game.Move{move}))
+		}
+	}
+	final, err := Replay(examplePuzzle(t), moves)
+	if err != nil {
+		t.Fatalf("constrained solution does not replay: %v", err)
+	}
+	if final.CalculateScore() != score {
+		t.Errorf("constrained solution replays to %d, reported %d", final.CalculateScore(), score)
+	}
+}
+
+func TestStatsSolved(t *testing.T) {
+	clearable := quietSolver(uniformPuzzle(t, 13, []int{1}))
+	clearable.SolveMonteCarlo(20)
+	if !clearable.LastStats().Solved {
+		t.Error("Stats.Solved is false after clearing a pyramid of 13s")
+	}
+	stuck := quietSolver(uniformPuzzle(t, 7, []int{1}))
+	stuck.SolveMonteCarlo(20)
+	if stuck.LastStats().Solved {
+		t.Error("Stats.Solved is true for a pyramid of 7s, which cannot be cleared")
+	}
+}
+
+func TestSolveMonteCarloSeededIsReproducible(t *testing.T) {
+	const runs = 5
+	wantMoves, wantScore := quietSolver(examplePuzzle(t)).SolveMonteCarloSeeded(500, 7)
+	for i := 1; i < runs; i++ {
+		moves, score := quietSolver(examplePuzzle(t)).SolveMonteCarloSeeded(500, 7)
+		if score != wantScore || game.EncodeMoves(moves) != game.EncodeMoves(wantMoves) {
+			t.Fatalf("run %d gave %s (%d), want %s (%d)", i, game.EncodeMoves(moves), score, game.EncodeMoves(wantMoves), wantScore)
+		}
+	}
+}
+
+func TestSmashGatedThirteens(t *testing.T) {
+	// Smashing the 13 at A1 is the only clear; the rest is 7s, which never match each other
+	pyramid := make([]int, game.TotalPyramidStones)
+	for i := range pyramid {
+		pyramid[i] = 7
+	}
+	pyramid[0] = 13
+	g := game.NewPuzzleGame()
+	if err := g.SetupCustomGame(pyramid, []int{1, 1, 1}); err != nil {
+		t.Fatalf("SetupCustomGame: %v", err)
+	}
+	smash := game.Move{Source: "A1", Destination: "SMASH"}
+
+	picks := func(gated bool) (smashes int) {
+		s := quietSolver(g)
+		s.SmashGatedThirteens = gated
+		r := rand.New(rand.NewSource(1))
+		tempGame := g.DeepCopy()
+		for i := 0; i < 100; i++ {
+			if s.chooseMove(r, g, tempGame, s.getPossibleMovesForSimulation(g)) == smash {
+				smashes++
+			}
+		}
+		return smashes
+	}
+	if got := picks(true); got != 100 {
+		t.Errorf("with SmashGatedThirteens the gating 13 was smashed %d of 100 times, want every time", got)
+	}
+	if got := picks(false); got == 100 {
+		t.Error("without SmashGatedThirteens the gating 13 was smashed every time, want some other moves")
+	}
+}
+
+func TestFreshMovesSkipsRevisits(t *testing.T) {
+	g := examplePuzzle(t)
+	draw := game.Move{Source: "DRAW", Destination: "DRAW"}
+	afterDraw := g.DeepCopy()
+	afterDraw.MakeMove(draw.Source, draw.Destination)
+	seen := map[uint64]bool{afterDraw.BoardHash(): true}
+
+	moves := g.LegalMoves()
+	fresh := freshMoves(g, g.DeepCopy(), moves, seen)
+	if containsMove(fresh, draw) {
+		t.Error("freshMoves kept a draw back to a position already seen")
+	}
+	if len(fresh) != len(moves)-1 {
+		t.Errorf("freshMoves kept %d of %d moves, want all but the draw", len(fresh), len(moves))
+	}
+	if only := freshMoves(g, g.DeepCopy(), []game.Move{draw}, seen); len(only) != 1 {
+		t.Errorf("freshMoves dropped the only move left, want it kept")
+	}
+}
+
+func TestQuietVerbosityPrintsNothing(t *testing.T) {
+	var out bytes.Buffer
+	s := NewPuzzleSolver(examplePuzzle(t))
+	s.Output = &out
+	s.Verbosity = Quiet
+	s.SolveMonteCarlo(100)
+	if out.Len() != 0 {
+		t.Errorf("quiet solve printed:\n%s", out.String())
+	}
+
+	s.Verbosity = Normal
+	s.SolveMonteCarlo(100)
+	if out.Len() == 0 {
+		t.Error("solve at Normal verbosity printed nothing")
+	}
+}
+
+func TestUnstrandingMovesKeepsLastPartner(t *testing.T) {
+	// Three 1s but a single 2, on DRW1: matching it with A1 strands the 1s at A3 and A5
+	pyramid := make([]int, game.TotalPyramidStones)
+	for i := range pyramid {
+		pyramid[i] = 13
+	}
+	pyramid[0], pyramid[2], pyramid[4] = 1, 1, 1
+	g := game.NewPuzzleGame()
+	if err := g.SetupCustomGame(pyramid, []int{2}); err != nil {
+		t.Fatalf("SetupCustomGame: %v", err)
+	}
+	stranding := game.Move{Source: "A1", Destination: "DRW1"}
+	moves := g.LegalMoves()
+	if !containsMove(moves, stranding) {
+		t.Fatalf("%v is not legal; the setup is wrong", stranding)
+	}
+
+	safe := unstrandingMoves(g, g.DeepCopy(), moves)
+	if containsMove(safe, stranding) {
+		t.Errorf("unstrandingMoves kept %v, which strands two 1s", stranding)
+	}
+	if !containsMove(safe, game.Move{Source: "A2", Destination: "SMASH"}) {
+		t.Error("unstrandingMoves dropped a harmless smash")
+	}
+	if only := unstrandingMoves(g, g.DeepCopy(), []game.Move{stranding}); len(only) != 1 {
+		t.Error("unstrandingMoves dropped the only move left, want it kept")
+	}
+}
+
+func TestRankOpeningMovesListsEachOpeningOnce(t *testing.T) {
+	g := examplePuzzle(t)
+	ranking := quietSolver(g).RankOpeningMoves(20)
+
+	openings := map[game.Move]bool{}
+	for _, move := range g.LegalMoves() {
+		openings[game.CanonicalizeMove(move)] = true
+	}
+	if len(ranking) != len(openings) {
+		t.Errorf("ranking has %d openings, want the %d distinct legal ones", len(ranking), len(openings))
+	}
+	listed := map[game.Move]bool{}
+	for i, entry := range ranking {
+		if listed[entry.Move] {
+			t.Errorf("opening %v is listed twice", entry.Move)
+		}
+		listed[entry.Move] = true
+		if !openings[entry.Move] {
+			t.Errorf("opening %v is not a legal opening", entry.Move)
+		}
+		if i > 0 && entry.MeanScore > ranking[i-1].MeanScore {
+			t.Errorf("opening %d has mean %v, above the one before it (%v)", i, entry.MeanScore, ranking[i-1].MeanScore)
+		}
+	}
+}
+
+func TestPruneHoldParksShrinksCandidates(t *testing.T) {
+	// 7s are never worth parking: no 8 is left anywhere. The 1 at A1 has a 2 waiting as DRW1
+	pyramid := make([]int, game.TotalPyramidStones)
+	for i := range pyramid {
+		pyramid[i] = 7
+	}
+	pyramid[0] = 1
+	g := game.NewPuzzleGame()
+	if err := g.SetupCustomGame(pyramid, []int{9, 9, 2}); err != nil {
+		t.Fatalf("SetupCustomGame: %v", err)
+	}
+
+	s := quietSolver(g)
+	all := s.getPossibleMovesForSimulation(g)
+	s.PruneHoldParks = true
+	pruned := s.getPossibleMovesForSimulation(g)
+	if len(pruned) >= len(all) {
+		t.Fatalf("pruning kept %d of %d candidates, want fewer", len(pruned), len(all))
+	}
+	for _, move := range all {
+		park := move.Destination == "HOLD" && move.Source != "DRW1"
+		want := !park || move.Source == "A1"
+		if got := containsMove(pruned, move); got != want {
+			t.Errorf("pruned candidates contain %v: %v, want %v", move, got, want)
+		}
+	}
+}
+
+func TestExtrapolateSolveTime(t *testing.T) {
+	const perRollout = 10 * time.Microsecond
+	if one, two := extrapolateSolveTime(perRollout, 1000, 4), extrapolateSolveTime(perRollout, 2000, 4); two != 2*one {
+		t.Errorf("2000 rollouts estimated at %v, want twice the %v for 1000", two, one)
+	}
+	if got, want := extrapolateSolveTime(perRollout, 1000, 0), 1000*perRollout; got != want {
+		t.Errorf("estimate with no workers = %v, want one worker's %v", got, want)
+	}
+	if estimate := quietSolver(examplePuzzle(t)).EstimateSolveTime(100_000); estimate <= 0 {
+		t.Errorf("EstimateSolveTime(100000) = %v, want a positive duration", estimate)
+	}
+}
+
+func TestSolveMonteCarloWithWorst(t *testing.T) {
+	g := examplePuzzle(t)
+	best, bestScore, worst, worstScore := quietSolver(g).SolveMonteCarloWithWorst(300)
+	if worstScore > bestScore {
+		t.Errorf("worst score %d is above the best %d", worstScore, bestScore)
+	}
+	for name, run := range map[string]struct {
+		moves []game.Move
+		score int
+	}{"best": {best, bestScore}, "worst": {worst, worstScore}} {
+		final, err := Replay(g, run.moves)
+		if err != nil {
+			t.Fatalf("%s moves do not replay: %v", name, err)
+		}
+		if final.CalculateScore() != run.score {
+			t.Errorf("%s moves replay to %d, reported %d", name, final.CalculateScore(), run.score)
+		}
+	}
+}
+
+func TestSolveWithUnknownDrawStones(t *testing.T) {
+	g := game.NewPuzzleGame()
+	pyramid := []int{12, 10, 11, 6, 11, 7, 12, 11, 5, 1, 4, 1, 4, 5, 10, 8, 11, 9, 7, 2, 9, 6, 2, 13, 9, 10, 12, 13}
+	drawPile := []int{6, 3, 8, 9, 3, 10, 2, 13, 6, 7, 1, 13, 12, 4, 1, 2, 3, 8, 5, 3, 5, 7, 3, 8}
+	drawPile[2], drawPile[4] = utils.UnknownStone, utils.UnknownStone
+	if err := g.SetupCustomGame(pyramid, drawPile); err != nil {
+		t.Fatalf("SetupCustomGame: %v", err)
+	}
+
+	s := quietSolver(g)
+	for seed := int64(1); seed <= 3; seed++ {
+		moves, score := s.SolveMonteCarloSeeded(200, seed)
+		start := s.LastStart()
+		if start.UnknownDrawStones() != 0 {
+			t.Fatalf("seed %d: LastStart has %d unknown stones, want them sampled", seed, start.UnknownDrawStones())
+		}
+		final, err := Replay(start, moves)
+		if err != nil {
+			t.Fatalf("seed %d: moves do not replay on the sampled pile: %v", seed, err)
+		}
+		if final.CalculateScore() != score {
+			t.Errorf("seed %d: moves replay to %d on the sampled pile, solver reported %d", seed, final.CalculateScore(), score)
+		}
+	}
+
+	if _, err := Replay(g, nil); !errors.Is(err, ErrUnknownDrawStones) {
+		t.Errorf("Replay on the unknown pile: error %v, want ErrUnknownDrawStones", err)
+	}
+	if _, err := ExportReplay(g, nil); !errors.Is(err, ErrUnknownDrawStones) {
+		t.Errorf("ExportReplay on the unknown pile: error %v, want ErrUnknownDrawStones", err)
+	}
+	final, score := s.SolveMonteCarloGame(200)
+	if final.CalculateScore() != score {
+		t.Errorf("SolveMonteCarloGame state scores %d, reported %d", final.CalculateScore(), score)
+	}
+}
@@ -0,0 +1,29 @@
+// Package solvertest provides helpers for tests of solvers and of code built on them.
+package solvertest
+
+import (
+	"errors"
+	"testing"
+
+	"pyramid_solver_go_local/game"
+	"pyramid_solver_go_local/solver"
+)
+
+// AssertValidSolution replays moves on a copy of g, failing t at the first move that is not
+// legal at that point, and then checks that the final score is expectedScore. g is not
+// modified.
+func AssertValidSolution(t testing.TB, g *game.PuzzleGame, moves []game.Move, expectedScore int) {
+	t.Helper()
+	final, err := solver.Replay(g, moves)
+	if err != nil {
+		var illegal *solver.IllegalMoveError
+		if errors.As(err, &illegal) {
+			t.Fatalf("move %d of %d (%s) is not legal: %s", illegal.Index, len(moves),
+				illegal.Move.Describe(), game.EncodeMoves(moves))
+		}
+		t.Fatalf("replaying solution: %v", err)
+	}
+	if score := final.CalculateScore(); score != expectedScore {
+		t.Fatalf("solution scores %d, want %d", score, expectedScore)
+	}
+}
@@ -0,0 +1,69 @@
+package solvertest
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"pyramid_solver_go_local/game"
+	"pyramid_solver_go_local/solver"
+)
+
+// fakeTB records the first Fatalf call and, like testing.T, stops the calling goroutine there.
+type fakeTB struct {
+	testing.TB
+	failed  bool
+	message string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Fatalf(format string, args ...any) {
+	f.failed = true
+	f.message = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
+
+// runAssert calls AssertValidSolution with a fakeTB and returns it once the call is over.
+func runAssert(g *game.PuzzleGame, moves []game.Move, expectedScore int) *fakeTB {
+	tb := &fakeTB{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		AssertValidSolution(tb, g, moves, expectedScore)
+	}()
+	<-done
+	return tb
+}
+
+func examplePuzzle(t *testing.T) *game.PuzzleGame {
+	t.Helper()
+	g := game.NewPuzzleGame()
+	pyramid := []int{12, 10, 11, 6, 11, 7, 12, 11, 5, 1, 4, 1, 4, 5, 10, 8, 11, 9, 7, 2, 9, 6, 2, 13, 9, 10, 12, 13}
+	drawPile := []int{6, 3, 8, 9, 3, 10, 2, 13, 6, 7, 1, 13, 12, 4, 1, 2, 3, 8, 5, 3, 5, 7, 3, 8}
+	if err := g.SetupCustomGame(pyramid, drawPile); err != nil {
+		t.Fatalf("SetupCustomGame: %v", err)
+	}
+	return g
+}
+
+func TestAssertValidSolution(t *testing.T) {
+	g := examplePuzzle(t)
+	s := solver.NewPuzzleSolver(g)
+	s.Verbosity = solver.Quiet
+	moves, score := s.SolveMonteCarlo(200)
+
+	if tb := runAssert(g, moves, score); tb.failed {
+		t.Errorf("a valid solution failed: %s", tb.message)
+	}
+	if tb := runAssert(g, moves, score+50); !tb.failed || !strings.Contains(tb.message, fmt.Sprintf("want %d", score+50)) {
+		t.Errorf("a wrong score was not reported, got failed %v: %q", tb.failed, tb.message)
+	}
+
+	illegal := append([]game.Move{}, moves...)
+	illegal[1] = game.Move{Source: "G1", Destination: "SMASH"}
+	if tb := runAssert(g, illegal, score); !tb.failed || !strings.Contains(tb.message, "move 1 of") {
+		t.Errorf("an illegal move was not reported at its index, got failed %v: %q", tb.failed, tb.message)
+	}
+}
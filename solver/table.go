@@ -0,0 +1,54 @@
+package solver
+
+import "math/rand"
+
+// tableEvictionSeed seeds the choice of evicted states, so a bounded search is repeatable.
+const tableEvictionSeed = 1
+
+// stateTable is the set of state hashes a search has already visited. With a positive
+// maxEntries it never holds more than that many: adding to a full table evicts a random entry,
+// so the search may later explore an evicted state again.
+type stateTable struct {
+	maxEntries int
+	index      map[uint64]int // Hash -> position in hashes
+	hashes     []uint64
+	r          *rand.Rand
+}
+
+// newStateTable returns an empty table holding at most maxEntries hashes, or any number if
+// maxEntries is zero.
+func newStateTable(maxEntries int) *stateTable {
+	return &stateTable{
+		maxEntries: maxEntries,
+		index:      make(map[uint64]int),
+		r:          rand.New(rand.NewSource(tableEvictionSeed)),
+	}
+}
+
+// contains reports whether hash is in the table.
+func (t *stateTable) contains(hash uint64) bool {
+	_, ok := t.index[hash]
+	return ok
+}
+
+// add puts hash in the table, first evicting a random entry if the table is full.
+func (t *stateTable) add(hash uint64) {
+	if t.contains(hash) {
+		return
+	}
+	if t.maxEntries > 0 && len(t.hashes) >= t.maxEntries {
+		victim := t.r.Intn(len(t.hashes))
+		evicted, last := t.hashes[victim], t.hashes[len(t.hashes)-1]
+		t.hashes[victim] = last
+		t.index[last] = victim
+		delete(t.index, evicted)
+		t.hashes = t.hashes[:len(t.hashes)-1]
+	}
+	t.index[hash] = len(t.hashes)
+	t.hashes = append(t.hashes, hash)
+}
+
+// len returns the number of hashes in the table.
+func (t *stateTable) len() int {
+	return len(t.hashes)
+}
@@ -0,0 +1,45 @@
+package solver
+
+import "testing"
+
+func TestStateTableEvictsWhenFull(t *testing.T) {
+	table := newStateTable(3)
+	for hash := uint64(1); hash <= 10; hash++ {
+		table.add(hash)
+		if table.len() > 3 {
+			t.Fatalf("table holds %d hashes after adding %d, want at most 3", table.len(), hash)
+		}
+		if !table.contains(hash) {
+			t.Fatalf("table does not contain %d just after adding it", hash)
+		}
+	}
+	for _, hash := range table.hashes {
+		if table.hashes[table.index[hash]] != hash {
+			t.Errorf("index of %d points at %d", hash, table.hashes[table.index[hash]])
+		}
+	}
+	if len(table.index) != table.len() {
+		t.Errorf("index has %d entries for %d hashes", len(table.index), table.len())
+	}
+
+	table.add(table.hashes[0])
+	if table.len() != 3 {
+		t.Errorf("adding a hash already held changed the size to %d", table.len())
+	}
+
+	unbounded := newStateTable(0)
+	for hash := uint64(1); hash <= 100; hash++ {
+		unbounded.add(hash)
+	}
+	if unbounded.len() != 100 {
+		t.Errorf("unbounded table holds %d hashes, want 100", unbounded.len())
+	}
+}
+
+func TestFindAnySolutionWithSmallTable(t *testing.T) {
+	s := quietSolver(drawMatchPuzzle(t))
+	s.MaxTableEntries = 4
+	if _, ok := s.FindAnySolution(10_000); !ok {
+		t.Error("FindAnySolution with a 4-entry table found no clear of a solvable puzzle")
+	}
+}
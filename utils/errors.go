@@ -0,0 +1,15 @@
+package utils
+
+import "errors"
+
+// Sentinel errors for puzzle input parsing. Parsers wrap them with %w, so callers can tell
+// failures apart with errors.Is.
+var (
+	// ErrInvalidStoneChar reports a character that is not one of the stone letters.
+	ErrInvalidStoneChar = errors.New("invalid stone character")
+	// ErrWrongLength reports an input with the wrong number of stones or values.
+	ErrWrongLength = errors.New("wrong number of stones")
+	// ErrOutOfRange reports a stone value outside 1-13, or pyramid coordinates outside the
+	// pyramid.
+	ErrOutOfRange = errors.New("stone value out of range")
+)
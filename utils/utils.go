@@ -78,7 +78,7 @@ func IndicesToString(rowIdx, colIdx int) (string, error) {
 func ParseInts(s string, expectedCount int) ([]int, error) {
 	parts := strings.Fields(s)
 	if len(parts) != expectedCount {
-		return nil, fmt.Errorf("expected %d values, got %d", expectedCount, len(parts))
+		return nil, fmt.Errorf("%w: expected %d values, got %d", ErrWrongLength, expectedCount, len(parts))
 	}
 	nums := make([]int, expectedCount)
 	for i, p := range parts {
@@ -87,13 +87,58 @@ func ParseInts(s string, expectedCount int) ([]int, error) {
 			return nil, fmt.Errorf("invalid number '%s': %w", p, err)
 		}
 		if n < 1 || n > 13 {
-			return nil, fmt.Errorf("value %d out of range (1-13)", n)
+			return nil, fmt.Errorf("%w: %d is not between 1 and 13", ErrOutOfRange, n)
 		}
 		nums[i] = n
 	}
 	return nums, nil
 }
 
+// StoneLetters holds the input letter of each stone value: value v is StoneLetters[v-1].
+const StoneLetters = "asdfghjklrtyu"
+
+// UnknownStone is the value of a draw pile stone that cannot be seen, written as UnknownLetter.
+const (
+	UnknownStone  = 0
+	UnknownLetter = '?'
+)
+
+// LetterToStone converts an input letter to its stone value, UnknownLetter to UnknownStone.
+func LetterToStone(letter rune) (int, error) {
+	if letter == UnknownLetter {
+		return UnknownStone, nil
+	}
+	if i := strings.IndexRune(StoneLetters, letter); i >= 0 {
+		return i + 1, nil
+	}
+	return -1, fmt.Errorf("%w: %c", ErrInvalidStoneChar, letter)
+}
+
+// StoneToLetter converts a stone value back to its input letter; the inverse of LetterToStone.
+func StoneToLetter(stone int) (rune, error) {
+	if stone == UnknownStone {
+		return UnknownLetter, nil
+	}
+	if stone < 1 || stone > len(StoneLetters) {
+		return 0, fmt.Errorf("%w: %d", ErrOutOfRange, stone)
+	}
+	return rune(StoneLetters[stone-1]), nil
+}
+
+// StonesToLetters converts stone values to their input letters, writing UnknownLetter for any
+// value without one.
+func StonesToLetters(stones []int) string {
+	var sb strings.Builder
+	for _, stone := range stones {
+		letter, err := StoneToLetter(stone)
+		if err != nil {
+			letter = '?'
+		}
+		sb.WriteRune(letter)
+	}
+	return sb.String()
+}
+
 // ShuffleArray shuffles a slice of integers.
 func ShuffleArray(arr []int) []int {
 	shuffled := make([]int, len(arr))
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseErrorsWrapSentinels(t *testing.T) {
+	_, wrongLength := ParseInts("1 2 3", 4)
+	_, outOfRange := ParseInts("1 2 14", 3)
+	_, invalidChar := LetterToStone('z')
+	_, badValue := StoneToLetter(14)
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"ParseInts with too few values", wrongLength, ErrWrongLength},
+		{"ParseInts with a 14", outOfRange, ErrOutOfRange},
+		{"LetterToStone('z')", invalidChar, ErrInvalidStoneChar},
+		{"StoneToLetter(14)", badValue, ErrOutOfRange},
+	}
+	for _, tt := range tests {
+		if !errors.Is(tt.err, tt.want) {
+			t.Errorf("%s: error %v does not wrap %v", tt.name, tt.err, tt.want)
+		}
+	}
+}